@@ -0,0 +1,125 @@
+package cert
+
+import (
+	"bufio"
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/cretz/owncast/owncast/cert/cast_certificate"
+	"github.com/cretz/owncast/owncast/log"
+	"github.com/golang/protobuf/proto"
+)
+
+// RevocationList is a set of revoked certs identified by the SHA-256 hash of their SubjectPublicKeyInfo which is how
+// Cast CRLs revoke certs
+type RevocationList struct {
+	PublicKeyHashes [][]byte
+}
+
+// LoadRevocationListFromFile loads hex-encoded public key hashes, one per line
+func LoadRevocationListFromFile(file string) (*RevocationList, error) {
+	log.Debugf("Loading revocation list from %v", file)
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	ret := &RevocationList{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		hash, err := hex.DecodeString(line)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid public key hash %v: %v", line, err)
+		}
+		ret.PublicKeyHashes = append(ret.PublicKeyHashes, hash)
+	}
+	return ret, scanner.Err()
+}
+
+func (r *RevocationList) PersistToFile(file string) error {
+	log.Debugf("Writing revocation list to %v", file)
+	var buf bytes.Buffer
+	for _, hash := range r.PublicKeyHashes {
+		buf.WriteString(hex.EncodeToString(hash) + "\n")
+	}
+	return ioutil.WriteFile(file, buf.Bytes(), 0600)
+}
+
+// PublicKeyHash returns the SHA-256 hash of the cert's SubjectPublicKeyInfo
+func PublicKeyHash(certDERBytes []byte) ([]byte, error) {
+	cert, err := x509.ParseCertificate(certDERBytes)
+	if err != nil {
+		return nil, fmt.Errorf("Failed parsing cert: %v", err)
+	}
+	hash := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+	return hash[:], nil
+}
+
+// Revoke adds the cert to the list, returning false if it was already present
+func (r *RevocationList) Revoke(certDERBytes []byte) (bool, error) {
+	hash, err := PublicKeyHash(certDERBytes)
+	if err != nil {
+		return false, err
+	}
+	if r.hasHash(hash) {
+		return false, nil
+	}
+	r.PublicKeyHashes = append(r.PublicKeyHashes, hash)
+	return true, nil
+}
+
+// IsRevoked returns whether the cert's public key is in the list
+func (r *RevocationList) IsRevoked(certDERBytes []byte) (bool, error) {
+	hash, err := PublicKeyHash(certDERBytes)
+	if err != nil {
+		return false, err
+	}
+	return r.hasHash(hash), nil
+}
+
+func (r *RevocationList) hasHash(hash []byte) bool {
+	for _, existing := range r.PublicKeyHashes {
+		if bytes.Equal(existing, hash) {
+			return true
+		}
+	}
+	return false
+}
+
+// GenerateCRL creates a serialized Cast CrlBundle revoking every cert in the list, signed by the signer (usually the
+// root CA) and valid for the inclusive time range
+func (r *RevocationList) GenerateCRL(signer *KeyPair, notBefore time.Time, notAfter time.Time) ([]byte, error) {
+	tbsBytes, err := proto.Marshal(&cast_certificate.TbsCrl{
+		Version:                proto.Uint64(0),
+		NotBeforeSeconds:       proto.Uint64(uint64(notBefore.Unix())),
+		NotAfterSeconds:        proto.Uint64(uint64(notAfter.Unix())),
+		RevokedPublicKeyHashes: r.PublicKeyHashes,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Failed marshalling TBS CRL: %v", err)
+	}
+	hashed := sha256.Sum256(tbsBytes)
+	sig, err := rsa.SignPKCS1v15(rand.Reader, signer.PrivKey, crypto.SHA256, hashed[:])
+	if err != nil {
+		return nil, fmt.Errorf("Failed signing CRL: %v", err)
+	}
+	return proto.Marshal(&cast_certificate.CrlBundle{
+		Crls: []*cast_certificate.Crl{
+			&cast_certificate.Crl{TbsCrl: tbsBytes, SignerCert: signer.DERBytes, Signature: sig},
+		},
+	})
+}
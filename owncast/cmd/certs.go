@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/cretz/owncast/owncast/cert"
+	"github.com/cretz/owncast/owncast/log"
+)
+
+// Names of the certs serve persists in the cert dir as <name>.crt and <name>.key, also accepted by revoke
+var generatedCertNames = []string{"intermediate", "peer", "auth"}
+
+// Generated certs expiring within this are regenerated
+const certRenewBefore = time.Hour
+
+// loadOrGenerateCerts loads the intermediate, peer and auth certs from the cert dir. Ones that are missing, revoked or
+// about to expire are generated and saved. The peer and auth certs are regenerated if their intermediate is.
+func loadOrGenerateCerts(
+	rootCA *cert.KeyPair,
+	revoked *cert.RevocationList,
+) (inter, peer, auth *cert.KeyPair, err error) {
+	if inter, err = loadGeneratedCert("intermediate", revoked); err != nil {
+		return
+	} else if inter == nil {
+		log.Infof("Generating intermediate CA cert in %v", certDir)
+		if inter, err = cert.GenerateIntermediateCAKeyPair(rootCA, nil, nil); err != nil {
+			return nil, nil, nil, fmt.Errorf("Unable to generate intermediate cert: %v", err)
+		} else if err = persistGeneratedCert("intermediate", inter); err != nil {
+			return
+		}
+		// Anything issued by the old one is useless
+		for _, name := range []string{"peer", "auth"} {
+			if err = os.Remove(filepath.Join(certDir, name+".crt")); err != nil && !os.IsNotExist(err) {
+				return nil, nil, nil, fmt.Errorf("Failed removing old %v cert: %v", name, err)
+			}
+		}
+	}
+	if peer, err = loadOrGenerateStandardCert("peer", inter, revoked); err != nil {
+		return
+	}
+	auth, err = loadOrGenerateStandardCert("auth", inter, revoked)
+	return
+}
+
+func loadOrGenerateStandardCert(name string, inter *cert.KeyPair, revoked *cert.RevocationList) (*cert.KeyPair, error) {
+	kp, err := loadGeneratedCert(name, revoked)
+	if err != nil || kp != nil {
+		return kp, err
+	}
+	log.Debugf("Generating %v cert in %v", name, certDir)
+	if kp, err = cert.GenerateStandardKeyPair(inter, nil, nil); err != nil {
+		return nil, fmt.Errorf("Unable to generate %v cert: %v", name, err)
+	}
+	return kp, persistGeneratedCert(name, kp)
+}
+
+// loadGeneratedCert returns nil if the cert is missing, revoked or about to expire
+func loadGeneratedCert(name string, revoked *cert.RevocationList) (*cert.KeyPair, error) {
+	kp, err := cert.LoadFromFiles(filepath.Join(certDir, name+".crt"), filepath.Join(certDir, name+".key"))
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("Failed loading %v cert: %v", name, err)
+	}
+	x509Cert, err := kp.CreateX509Certificate()
+	if err != nil {
+		return nil, fmt.Errorf("Failed parsing %v cert: %v", name, err)
+	}
+	if time.Now().Add(certRenewBefore).After(x509Cert.NotAfter) {
+		log.Debugf("The %v cert expires at %v, regenerating", name, x509Cert.NotAfter)
+		return nil, nil
+	}
+	if revoked != nil {
+		if isRevoked, err := revoked.IsRevoked(kp.DERBytes); err != nil {
+			return nil, err
+		} else if isRevoked {
+			log.Infof("The %v cert is revoked, regenerating", name)
+			return nil, nil
+		}
+	}
+	return kp, nil
+}
+
+func persistGeneratedCert(name string, kp *cert.KeyPair) error {
+	if err := kp.PersistToFiles(filepath.Join(certDir, name+".crt"), filepath.Join(certDir, name+".key")); err != nil {
+		return fmt.Errorf("Failed saving %v cert: %v", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/cretz/owncast/owncast/cert"
+	"github.com/cretz/owncast/owncast/log"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	revokeCmd := &cobra.Command{
+		// Besides cert files, the certs serve saves in the cert dir can be given by name. Serve regenerates revoked ones.
+		Use:  "revoke [cert file or intermediate|peer|auth...]",
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			revokedFile := filepath.Join(certDir, "revoked.txt")
+			revoked, err := cert.LoadRevocationListFromFile(revokedFile)
+			if os.IsNotExist(err) {
+				revoked, err = &cert.RevocationList{}, nil
+			}
+			if err != nil {
+				return fmt.Errorf("Failed loading revoked.txt: %v", err)
+			}
+			for _, certFile := range args {
+				for _, name := range generatedCertNames {
+					if certFile == name {
+						certFile = filepath.Join(certDir, name+".crt")
+					}
+				}
+				certBytes, err := ioutil.ReadFile(certFile)
+				if err != nil {
+					return fmt.Errorf("Failed reading cert: %v", err)
+				}
+				certPEM, _ := pem.Decode(certBytes)
+				if certPEM == nil {
+					return fmt.Errorf("No PEM cert in %v", certFile)
+				}
+				if added, err := revoked.Revoke(certPEM.Bytes); err != nil {
+					return fmt.Errorf("Failed revoking %v: %v", certFile, err)
+				} else if added {
+					log.Infof("Revoked %v", certFile)
+				} else {
+					log.Infof("Already revoked %v", certFile)
+				}
+			}
+			return revoked.PersistToFile(revokedFile)
+		},
+	}
+	rootCmd.AddCommand(revokeCmd)
+}
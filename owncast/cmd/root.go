@@ -28,7 +28,7 @@ var rootCmd = &cobra.Command{
 
 func init() {
 	rootCmd.PersistentFlags().StringVarP(&certDir,
-		"cert-dir", "d", ".", "Sets the dir to load/create ca.crt and ca.key")
+		"cert-dir", "d", ".", "Sets the dir to load/create ca.crt, ca.key, the certs serve uses and revoked.txt")
 	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "Show debug logs")
 	rootCmd.PersistentFlags().BoolVarP(&quiet, "quiet", "q", false, "Hide info logs")
 }
@@ -1,8 +1,12 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
+	"os"
+	"os/signal"
 	"path/filepath"
+	"time"
 
 	"github.com/cretz/owncast/owncast/cert"
 	"github.com/cretz/owncast/owncast/server"
@@ -10,22 +14,66 @@ import (
 )
 
 func init() {
+	var appsFile, httpAddr, volumeFile string
 	serveCmd := &cobra.Command{
 		Use: "serve",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			// Load root CA
-			rootCA, err := cert.LoadFromFiles(filepath.Join("ca.crt"), filepath.Join("ca.key"))
+			rootCA, err := cert.LoadFromFiles(filepath.Join(certDir, "ca.crt"), filepath.Join(certDir, "ca.key"))
 			if err != nil {
 				return fmt.Errorf("Failed loading ca.crt/ca.key, did you forget to run 'patch'? Err: %v", err)
 			}
+			conf := &server.Conf{RootCACert: rootCA, HTTPListenAddr: httpAddr, VolumeFile: volumeFile}
+			if appsFile != "" {
+				if conf.Apps, err = server.LoadAppRegistryFromFile(appsFile); err != nil {
+					return fmt.Errorf("Failed loading apps: %v", err)
+				}
+			}
+			revoked, err := cert.LoadRevocationListFromFile(filepath.Join(certDir, "revoked.txt"))
+			if err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("Failed loading revoked.txt: %v", err)
+			}
+			// Use the persisted certs so they can be revoked, replacing any that are
+			inter, peer, auth, err := loadOrGenerateCerts(rootCA, revoked)
+			if err != nil {
+				return err
+			}
+			conf.IntermediateCACerts, conf.PeerCert, conf.AuthCert = []*cert.KeyPair{inter}, peer, auth
+			// Build CRL from revoked certs if any
+			if revoked != nil && len(revoked.PublicKeyHashes) > 0 {
+				rootCACert, err := rootCA.CreateX509Certificate()
+				if err != nil {
+					return fmt.Errorf("Failed parsing root CA: %v", err)
+				}
+				// Valid as long as the signer is
+				conf.CRL, err = revoked.GenerateCRL(rootCA, time.Now().Add(-time.Hour), rootCACert.NotAfter)
+				if err != nil {
+					return fmt.Errorf("Failed generating CRL: %v", err)
+				}
+			}
 			// Start server
-			srv, err := server.Listen(&server.Conf{RootCACert: rootCA})
+			srv, err := server.Listen(conf)
 			if err != nil {
 				return fmt.Errorf("Unable to start server: %v", err)
 			}
-			// Use interactively
-			return server.RunServerInteractively(srv, server.StdioUserInput)
+			// Use interactively until interrupted
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			sigCh := make(chan os.Signal, 1)
+			signal.Notify(sigCh, os.Interrupt)
+			defer signal.Stop(sigCh)
+			go func() {
+				select {
+				case <-sigCh:
+					cancel()
+				case <-ctx.Done():
+				}
+			}()
+			return server.RunServerInteractively(ctx, srv, server.StdioUserInput)
 		},
 	}
+	serveCmd.Flags().StringVar(&appsFile, "apps", "", "JSON file of receiver apps to use instead of the defaults")
+	serveCmd.Flags().StringVar(&httpAddr, "http", "", "Address to serve web receiver apps on, e.g. :8008")
+	serveCmd.Flags().StringVar(&volumeFile, "volume-file", "", "JSON file to keep the volume in across restarts")
 	rootCmd.AddCommand(serveCmd)
 }
@@ -0,0 +1,134 @@
+package server
+
+import (
+	"fmt"
+
+	"github.com/cretz/owncast/owncast/log"
+	"github.com/cretz/owncast/owncast/server/cast_channel"
+)
+
+// App is receiver logic run in-process for a launched session. A new App is created from AppInfo.NewApp for each
+// launch. Hooks may be called concurrently from different connections.
+type App interface {
+	// Launched is called before the session is made visible. An error fails the launch. If it is a ReceiverError, it
+	// is what the sender is sent.
+	Launched(session *AppSession) error
+	SenderJoined(session *AppSession, sender *AppSender)
+	SenderLeft(session *AppSession, sender *AppSender)
+	// HandleMessage is called for every message a sender sends to the session's transport ID except on the
+	// connection namespace
+	HandleMessage(session *AppSession, sender *AppSender, msg Message) error
+	// Stopped is called after all senders have been closed
+	Stopped(session *AppSession)
+}
+
+// AppWithSenderRejoin is an App told when a sender's conn is lost and when it rejoins within the server's rejoin grace
+// period. Only a sender connecting to the session's transport ID with the same sender ID rejoins, one with a new ID is
+// a new sender. SenderLeft is still called if the period passes without a rejoin. Apps that don't implement this see a
+// lost sender as joined until it either rejoins, reported as SenderLeft for the old sender and SenderJoined for the
+// new, or the period passes.
+type AppWithSenderRejoin interface {
+	App
+	SenderLost(session *AppSession, sender *AppSender)
+	SenderRejoined(session *AppSession, sender *AppSender, previous *AppSender)
+}
+
+// BaseApp is an App that does nothing, meant to be embedded to only implement some hooks
+type BaseApp struct{}
+
+func (BaseApp) Launched(session *AppSession) error                                      { return nil }
+func (BaseApp) SenderJoined(session *AppSession, sender *AppSender)                     {}
+func (BaseApp) SenderLeft(session *AppSession, sender *AppSender)                       {}
+func (BaseApp) HandleMessage(session *AppSession, sender *AppSender, msg Message) error { return nil }
+func (BaseApp) Stopped(session *AppSession)                                             {}
+
+// SendPayload sends the JSON payload to the sender from the session's transport ID
+func (a *AppSender) SendPayload(namespace string, payload interface{}) error {
+	return a.Conn.SendPayload(a.session.TransportID, a.SenderID, namespace, payload)
+}
+
+// SendStringMessage sends the string to the sender from the session's transport ID
+func (a *AppSender) SendStringMessage(namespace string, str string) error {
+	return a.Conn.SendStringMessage(a.session.TransportID, a.SenderID, namespace, str)
+}
+
+// SendBinaryMessage sends the bytes to the sender from the session's transport ID
+func (a *AppSender) SendBinaryMessage(namespace string, byts []byte) error {
+	return a.Conn.SendBinaryMessage(a.session.TransportID, a.SenderID, namespace, byts)
+}
+
+// BroadcastPayload sends the JSON payload to every joined sender, returning the first error
+func (a *AppSession) BroadcastPayload(namespace string, payload interface{}) error {
+	var firstErr error
+	for _, sender := range a.Senders() {
+		if err := sender.SendPayload(namespace, payload); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// AppLine is the JSON message exchanged with apps that run outside of Go, such as ProcessApp and WebApp. Ones sent to
+// the app are "launched", "senderJoined", "senderLeft", "message" and "stopped". Ones received from the app must be
+// "message" on one of the app's namespaces and are sent to every joined sender with the sender ID, or all of them if it
+// is empty or "*". A message has either Data for string payloads or Binary for binary ones.
+type AppLine struct {
+	Type        string   `json:"type"`
+	AppID       string   `json:"appId,omitempty"`
+	DisplayName string   `json:"displayName,omitempty"`
+	Namespaces  []string `json:"namespaces,omitempty"`
+	SessionID   string   `json:"sessionId,omitempty"`
+	TransportID string   `json:"transportId,omitempty"`
+	SenderID    string   `json:"senderId,omitempty"`
+	Namespace   string   `json:"namespace,omitempty"`
+	Data        *string  `json:"data,omitempty"`
+	Binary      []byte   `json:"binary,omitempty"`
+}
+
+func (a *AppSession) launchedLine() *AppLine {
+	return &AppLine{
+		Type:        "launched",
+		AppID:       a.AppID,
+		DisplayName: a.DisplayName,
+		Namespaces:  a.Namespaces,
+		SessionID:   a.SessionID,
+		TransportID: a.TransportID,
+	}
+}
+
+func messageLine(sender *AppSender, castMsg *cast_channel.CastMessage) *AppLine {
+	line := &AppLine{Type: "message", SenderID: sender.SenderID, Namespace: castMsg.GetNamespace()}
+	if castMsg.GetPayloadType() == cast_channel.CastMessage_BINARY {
+		line.Binary = castMsg.PayloadBinary
+	} else {
+		line.Data = castMsg.PayloadUtf8
+	}
+	return line
+}
+
+// sendAppLine sends a message line from an app to its senders
+func (a *AppSession) sendAppLine(line *AppLine) error {
+	if line.Type != "message" {
+		return fmt.Errorf("Unknown type %v", line.Type)
+	} else if line.Namespace == "" {
+		return fmt.Errorf("Missing namespace")
+	} else if !a.hasNamespace(line.Namespace) {
+		// Otherwise the app could send e.g. CLOSE or RECEIVER_STATUS as the receiver
+		return fmt.Errorf("Namespace %v not one of the app's", line.Namespace)
+	}
+	for _, sender := range a.Senders() {
+		if line.SenderID != "" && line.SenderID != BroadcastID && line.SenderID != sender.SenderID {
+			continue
+		}
+		var err error
+		if line.Data != nil {
+			err = sender.SendStringMessage(line.Namespace, *line.Data)
+		} else {
+			err = sender.SendBinaryMessage(line.Namespace, line.Binary)
+		}
+		if err != nil {
+			log.Debugf("Failed sending app message to %v: %v", sender.SenderID, err)
+		}
+	}
+	return nil
+}
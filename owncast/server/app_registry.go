@@ -0,0 +1,144 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sync"
+
+	"github.com/cretz/owncast/owncast/log"
+)
+
+// AppInfo describes a receiver app that can be launched
+type AppInfo struct {
+	AppID       AppID    `json:"appId"`
+	DisplayName string   `json:"displayName"`
+	Namespaces  []string `json:"namespaces"`
+	IconURL     string   `json:"iconUrl,omitempty"`
+	// If true, the app is reported as unavailable and cannot be launched
+	Unavailable bool `json:"unavailable,omitempty"`
+	// If not empty and NewApp is nil, a ProcessApp is run for the command
+	Command []string `json:"command,omitempty"`
+	// If not empty and NewApp is nil and there is no command, a WebApp is run with the directory served over HTTP
+	Dir string `json:"dir,omitempty"`
+	// If nil and there is no command or dir, the session has no app and its messages are ignored
+	NewApp func() App `json:"-"`
+}
+
+// AppRegistry is the set of apps known by the receiver. It is safe for concurrent use.
+type AppRegistry struct {
+	apps     map[AppID]*AppInfo
+	appsLock sync.RWMutex
+}
+
+func NewAppRegistry(apps ...*AppInfo) *AppRegistry {
+	ret := &AppRegistry{apps: map[AppID]*AppInfo{}}
+	for _, app := range apps {
+		ret.Register(app)
+	}
+	return ret
+}
+
+// DefaultAppRegistry contains the default media receiver and the apps Chrome checks to offer tab mirroring
+func DefaultAppRegistry() *AppRegistry {
+	return NewAppRegistry(
+		&AppInfo{
+			AppID:       DefaultMediaReceiverAppID,
+			DisplayName: "Default Media Receiver",
+			Namespaces: []string{
+				"urn:x-cast:com.google.cast.player.message",
+				NamespaceMedia,
+			},
+		},
+		&AppInfo{
+			AppID:       MirroringAppID,
+			DisplayName: "Chrome Mirroring",
+			Namespaces:  []string{"urn:x-cast:com.google.cast.webrtc"},
+		},
+		&AppInfo{
+			AppID:       AudioMirroringAppID,
+			DisplayName: "Chrome Audio Mirroring",
+			Namespaces:  []string{"urn:x-cast:com.google.cast.webrtc"},
+		},
+	)
+}
+
+// LoadAppRegistryFromFile loads a JSON array of AppInfo
+func LoadAppRegistryFromFile(file string) (*AppRegistry, error) {
+	log.Debugf("Loading apps from %v", file)
+	byts, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var apps []*AppInfo
+	if err = json.Unmarshal(byts, &apps); err != nil {
+		return nil, fmt.Errorf("Failed parsing apps: %v", err)
+	}
+	for _, app := range apps {
+		if app.AppID == "" {
+			return nil, fmt.Errorf("App missing ID")
+		}
+	}
+	return NewAppRegistry(apps...), nil
+}
+
+// Register adds or replaces the app
+func (a *AppRegistry) Register(app *AppInfo) {
+	a.appsLock.Lock()
+	defer a.appsLock.Unlock()
+	appCopy := *app
+	appCopy.Namespaces = append([]string(nil), app.Namespaces...)
+	appCopy.Command = append([]string(nil), app.Command...)
+	a.apps[app.AppID] = &appCopy
+}
+
+// Unregister removes the app, returning false if it was not registered
+func (a *AppRegistry) Unregister(appID AppID) bool {
+	a.appsLock.Lock()
+	defer a.appsLock.Unlock()
+	_, ok := a.apps[appID]
+	delete(a.apps, appID)
+	return ok
+}
+
+// App returns a copy of the app or nil if not registered
+func (a *AppRegistry) App(appID AppID) *AppInfo {
+	a.appsLock.RLock()
+	defer a.appsLock.RUnlock()
+	app := a.apps[appID]
+	if app == nil {
+		return nil
+	}
+	appCopy := *app
+	appCopy.Namespaces = append([]string(nil), app.Namespaces...)
+	appCopy.Command = append([]string(nil), app.Command...)
+	return &appCopy
+}
+
+// Apps returns copies of all registered apps
+func (a *AppRegistry) Apps() []*AppInfo {
+	a.appsLock.RLock()
+	ids := make([]AppID, 0, len(a.apps))
+	for appID := range a.apps {
+		ids = append(ids, appID)
+	}
+	a.appsLock.RUnlock()
+	ret := make([]*AppInfo, 0, len(ids))
+	for _, appID := range ids {
+		if app := a.App(appID); app != nil {
+			ret = append(ret, app)
+		}
+	}
+	return ret
+}
+
+// Availability is AppAvailable if the app is registered and not marked unavailable
+func (a *AppRegistry) Availability(appID AppID) AppAvailability {
+	if app := a.App(appID); app != nil && !app.Unavailable {
+		return AppAvailable
+	}
+	return AppUnavailable
+}
+
+// Apps returns the server's app registry which can be changed while running
+func (s *Server) Apps() *AppRegistry { return s.apps }
@@ -54,7 +54,7 @@ func (x *SignatureAlgorithm) UnmarshalJSON(data []byte) error {
 	return nil
 }
 func (SignatureAlgorithm) EnumDescriptor() ([]byte, []int) {
-	return fileDescriptor_cast_channel_9a155f907c6bf486, []int{0}
+	return fileDescriptor_cast_channel_5d337c104c7f7f60, []int{0}
 }
 
 type HashAlgorithm int32
@@ -90,7 +90,7 @@ func (x *HashAlgorithm) UnmarshalJSON(data []byte) error {
 	return nil
 }
 func (HashAlgorithm) EnumDescriptor() ([]byte, []int) {
-	return fileDescriptor_cast_channel_9a155f907c6bf486, []int{1}
+	return fileDescriptor_cast_channel_5d337c104c7f7f60, []int{1}
 }
 
 // Always pass a version of the protocol for future compatibility
@@ -99,13 +99,22 @@ type CastMessage_ProtocolVersion int32
 
 const (
 	CastMessage_CASTV2_1_0 CastMessage_ProtocolVersion = 0
+	CastMessage_CASTV2_1_1 CastMessage_ProtocolVersion = 1
+	CastMessage_CASTV2_1_2 CastMessage_ProtocolVersion = 2
+	CastMessage_CASTV2_1_3 CastMessage_ProtocolVersion = 3
 )
 
 var CastMessage_ProtocolVersion_name = map[int32]string{
 	0: "CASTV2_1_0",
+	1: "CASTV2_1_1",
+	2: "CASTV2_1_2",
+	3: "CASTV2_1_3",
 }
 var CastMessage_ProtocolVersion_value = map[string]int32{
 	"CASTV2_1_0": 0,
+	"CASTV2_1_1": 1,
+	"CASTV2_1_2": 2,
+	"CASTV2_1_3": 3,
 }
 
 func (x CastMessage_ProtocolVersion) Enum() *CastMessage_ProtocolVersion {
@@ -125,7 +134,7 @@ func (x *CastMessage_ProtocolVersion) UnmarshalJSON(data []byte) error {
 	return nil
 }
 func (CastMessage_ProtocolVersion) EnumDescriptor() ([]byte, []int) {
-	return fileDescriptor_cast_channel_9a155f907c6bf486, []int{0, 0}
+	return fileDescriptor_cast_channel_5d337c104c7f7f60, []int{0, 0}
 }
 
 // What type of data do we have in this message.
@@ -162,7 +171,7 @@ func (x *CastMessage_PayloadType) UnmarshalJSON(data []byte) error {
 	return nil
 }
 func (CastMessage_PayloadType) EnumDescriptor() ([]byte, []int) {
-	return fileDescriptor_cast_channel_9a155f907c6bf486, []int{0, 1}
+	return fileDescriptor_cast_channel_5d337c104c7f7f60, []int{0, 1}
 }
 
 type AuthError_ErrorType int32
@@ -201,7 +210,7 @@ func (x *AuthError_ErrorType) UnmarshalJSON(data []byte) error {
 	return nil
 }
 func (AuthError_ErrorType) EnumDescriptor() ([]byte, []int) {
-	return fileDescriptor_cast_channel_9a155f907c6bf486, []int{3, 0}
+	return fileDescriptor_cast_channel_5d337c104c7f7f60, []int{3, 0}
 }
 
 type CastMessage struct {
@@ -229,8 +238,18 @@ type CastMessage struct {
 	PayloadType *CastMessage_PayloadType `protobuf:"varint,5,req,name=payload_type,json=payloadType,enum=cast_channel.CastMessage_PayloadType" json:"payload_type,omitempty"`
 	// Depending on payload_type, exactly one of the following optional fields
 	// will always be set.
-	PayloadUtf8          *string  `protobuf:"bytes,6,opt,name=payload_utf8,json=payloadUtf8" json:"payload_utf8,omitempty"`
-	PayloadBinary        []byte   `protobuf:"bytes,7,opt,name=payload_binary,json=payloadBinary" json:"payload_binary,omitempty"`
+	PayloadUtf8   *string `protobuf:"bytes,6,opt,name=payload_utf8,json=payloadUtf8" json:"payload_utf8,omitempty"`
+	PayloadBinary []byte  `protobuf:"bytes,7,opt,name=payload_binary,json=payloadBinary" json:"payload_binary,omitempty"`
+	// Flag indicating whether there are more chunks to follow for this message.
+	// If the flag is false or is not present, then this is the last (or only)
+	// chunk of the message.
+	Continued *bool `protobuf:"varint,8,opt,name=continued" json:"continued,omitempty"`
+	// If this is a chunk of a larger message, and the remaining length of the
+	// message payload (the sum of the lengths of the payloads of the remaining
+	// chunks) is known, this field will indicate that length. For a given
+	// chunked message, this field should either be present in all of the chunks,
+	// or in none of them.
+	RemainingLength      *uint32  `protobuf:"varint,9,opt,name=remaining_length,json=remainingLength" json:"remaining_length,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -240,7 +259,7 @@ func (m *CastMessage) Reset()         { *m = CastMessage{} }
 func (m *CastMessage) String() string { return proto.CompactTextString(m) }
 func (*CastMessage) ProtoMessage()    {}
 func (*CastMessage) Descriptor() ([]byte, []int) {
-	return fileDescriptor_cast_channel_9a155f907c6bf486, []int{0}
+	return fileDescriptor_cast_channel_5d337c104c7f7f60, []int{0}
 }
 func (m *CastMessage) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_CastMessage.Unmarshal(m, b)
@@ -309,6 +328,20 @@ func (m *CastMessage) GetPayloadBinary() []byte {
 	return nil
 }
 
+func (m *CastMessage) GetContinued() bool {
+	if m != nil && m.Continued != nil {
+		return *m.Continued
+	}
+	return false
+}
+
+func (m *CastMessage) GetRemainingLength() uint32 {
+	if m != nil && m.RemainingLength != nil {
+		return *m.RemainingLength
+	}
+	return 0
+}
+
 // Messages for authentication protocol between a sender and a receiver.
 type AuthChallenge struct {
 	SignatureAlgorithm   *SignatureAlgorithm `protobuf:"varint,1,opt,name=signature_algorithm,json=signatureAlgorithm,enum=cast_channel.SignatureAlgorithm,def=1" json:"signature_algorithm,omitempty"`
@@ -323,7 +356,7 @@ func (m *AuthChallenge) Reset()         { *m = AuthChallenge{} }
 func (m *AuthChallenge) String() string { return proto.CompactTextString(m) }
 func (*AuthChallenge) ProtoMessage()    {}
 func (*AuthChallenge) Descriptor() ([]byte, []int) {
-	return fileDescriptor_cast_channel_9a155f907c6bf486, []int{1}
+	return fileDescriptor_cast_channel_5d337c104c7f7f60, []int{1}
 }
 func (m *AuthChallenge) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_AuthChallenge.Unmarshal(m, b)
@@ -384,7 +417,7 @@ func (m *AuthResponse) Reset()         { *m = AuthResponse{} }
 func (m *AuthResponse) String() string { return proto.CompactTextString(m) }
 func (*AuthResponse) ProtoMessage()    {}
 func (*AuthResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_cast_channel_9a155f907c6bf486, []int{2}
+	return fileDescriptor_cast_channel_5d337c104c7f7f60, []int{2}
 }
 func (m *AuthResponse) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_AuthResponse.Unmarshal(m, b)
@@ -467,7 +500,7 @@ func (m *AuthError) Reset()         { *m = AuthError{} }
 func (m *AuthError) String() string { return proto.CompactTextString(m) }
 func (*AuthError) ProtoMessage()    {}
 func (*AuthError) Descriptor() ([]byte, []int) {
-	return fileDescriptor_cast_channel_9a155f907c6bf486, []int{3}
+	return fileDescriptor_cast_channel_5d337c104c7f7f60, []int{3}
 }
 func (m *AuthError) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_AuthError.Unmarshal(m, b)
@@ -509,7 +542,7 @@ func (m *DeviceAuthMessage) Reset()         { *m = DeviceAuthMessage{} }
 func (m *DeviceAuthMessage) String() string { return proto.CompactTextString(m) }
 func (*DeviceAuthMessage) ProtoMessage()    {}
 func (*DeviceAuthMessage) Descriptor() ([]byte, []int) {
-	return fileDescriptor_cast_channel_9a155f907c6bf486, []int{4}
+	return fileDescriptor_cast_channel_5d337c104c7f7f60, []int{4}
 }
 func (m *DeviceAuthMessage) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_DeviceAuthMessage.Unmarshal(m, b)
@@ -563,54 +596,57 @@ func init() {
 	proto.RegisterEnum("cast_channel.AuthError_ErrorType", AuthError_ErrorType_name, AuthError_ErrorType_value)
 }
 
-func init() { proto.RegisterFile("cast_channel.proto", fileDescriptor_cast_channel_9a155f907c6bf486) }
-
-var fileDescriptor_cast_channel_9a155f907c6bf486 = []byte{
-	// 734 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xb4, 0x54, 0xdd, 0x6a, 0xe3, 0x46,
-	0x18, 0x8d, 0xac, 0x24, 0x8d, 0x3e, 0xf9, 0x47, 0x9d, 0x50, 0x22, 0x9a, 0x42, 0x1d, 0x15, 0x83,
-	0x1b, 0xa8, 0xa9, 0x0d, 0x09, 0x49, 0xaf, 0x2a, 0x3b, 0x6e, 0xac, 0xd6, 0x51, 0xcc, 0x48, 0x0e,
-	0xf4, 0x4a, 0x4c, 0xe4, 0x89, 0x25, 0x50, 0x24, 0x21, 0x8d, 0x03, 0xbe, 0xed, 0x8b, 0xf4, 0xa2,
-	0xcf, 0xd0, 0xf7, 0xd9, 0x47, 0x59, 0x34, 0xb2, 0x6c, 0x29, 0x21, 0xcb, 0xb2, 0xb0, 0x37, 0x66,
-	0x7c, 0xe6, 0x7c, 0x9a, 0xf3, 0x9d, 0x39, 0xdf, 0x00, 0x72, 0x49, 0xca, 0x1c, 0xd7, 0x23, 0x61,
-	0x48, 0x83, 0x5e, 0x9c, 0x44, 0x2c, 0x42, 0xf5, 0x32, 0xa6, 0xfd, 0x27, 0x82, 0x3c, 0x22, 0x29,
-	0xbb, 0xa3, 0x69, 0x4a, 0x96, 0x14, 0xd9, 0xa0, 0x70, 0x9a, 0x1b, 0x05, 0xce, 0x0b, 0x4d, 0x52,
-	0x3f, 0x0a, 0x55, 0xa1, 0x5d, 0xeb, 0x36, 0x07, 0x3f, 0xf7, 0x2a, 0x1f, 0x2b, 0x15, 0xf5, 0x66,
-	0x9b, 0x8a, 0x87, 0xbc, 0x00, 0xb7, 0xe2, 0x2a, 0x80, 0x4e, 0x41, 0x4a, 0xa3, 0x55, 0xe2, 0x52,
-	0xc7, 0x5f, 0xa8, 0xb5, 0x76, 0xad, 0x2b, 0xe1, 0xa3, 0x1c, 0x30, 0x16, 0xa8, 0x03, 0xcd, 0x05,
-	0x4d, 0x99, 0x1f, 0x12, 0xe6, 0x47, 0x61, 0xc6, 0x10, 0x39, 0xa3, 0x51, 0x42, 0x8d, 0x05, 0xfa,
-	0x01, 0xa4, 0x90, 0x3c, 0xd3, 0x34, 0x26, 0x2e, 0x55, 0xf7, 0x39, 0x63, 0x07, 0xa0, 0x09, 0xd4,
-	0x63, 0xb2, 0x0e, 0x22, 0xb2, 0x70, 0xd8, 0x3a, 0xa6, 0xea, 0x01, 0xd7, 0xdc, 0xf9, 0x84, 0xe6,
-	0x9c, 0x6d, 0xaf, 0x63, 0x8a, 0xe5, 0x78, 0xf7, 0x07, 0x9d, 0xed, 0xbe, 0xb4, 0x62, 0x4f, 0x57,
-	0xea, 0x61, 0x5b, 0xe8, 0x4a, 0x5b, 0xca, 0x9c, 0x3d, 0x5d, 0x65, 0x8a, 0x0b, 0xca, 0xa3, 0x1f,
-	0x92, 0x64, 0xad, 0x7e, 0xd3, 0x16, 0xba, 0x75, 0xdc, 0xd8, 0xa0, 0x43, 0x0e, 0x6a, 0x67, 0xd0,
-	0x7a, 0xe5, 0x0c, 0x6a, 0x02, 0x8c, 0x74, 0xcb, 0x7e, 0x18, 0x38, 0x7d, 0xe7, 0x57, 0x65, 0x4f,
-	0xeb, 0x80, 0x5c, 0x12, 0x82, 0x00, 0x0e, 0x2d, 0x1b, 0x1b, 0xe6, 0xad, 0xb2, 0x97, 0xad, 0x87,
-	0x86, 0xa9, 0xe3, 0xbf, 0x15, 0x41, 0xfb, 0x20, 0x40, 0x43, 0x5f, 0x31, 0x6f, 0xe4, 0x91, 0x20,
-	0xa0, 0xe1, 0x92, 0xa2, 0x47, 0x38, 0x4e, 0xfd, 0x65, 0x48, 0xd8, 0x2a, 0xa1, 0x0e, 0x09, 0x96,
-	0x51, 0xe2, 0x33, 0xef, 0x59, 0x15, 0xda, 0x42, 0xb7, 0x39, 0x68, 0x57, 0xdb, 0xb6, 0x0a, 0xa2,
-	0x5e, 0xf0, 0x7e, 0x6b, 0x61, 0x4b, 0xb7, 0x2c, 0xdd, 0x99, 0xfd, 0x35, 0xb2, 0xfa, 0x2f, 0xfd,
-	0x0b, 0x8c, 0xd2, 0x37, 0xa4, 0xcc, 0x89, 0x94, 0x86, 0x0b, 0x9a, 0x38, 0x61, 0x14, 0xba, 0x54,
-	0xad, 0xf1, 0x26, 0xe5, 0x1c, 0x33, 0x33, 0x08, 0x4d, 0xa0, 0xe9, 0x91, 0xd4, 0x2b, 0x29, 0x10,
-	0xb9, 0x82, 0xd3, 0xaa, 0x82, 0x09, 0x49, 0xbd, 0xdd, 0xe1, 0xfb, 0xd6, 0x44, 0xef, 0xe3, 0x86,
-	0x57, 0x06, 0xb5, 0x7f, 0x44, 0xa8, 0x67, 0x2d, 0x62, 0x9a, 0xc6, 0x51, 0x98, 0xd2, 0xec, 0xbe,
-	0xb7, 0x9a, 0x78, 0x04, 0xeb, 0x78, 0x07, 0xa0, 0x4b, 0x38, 0x71, 0x03, 0x9f, 0x86, 0xcc, 0x21,
-	0x2b, 0xe6, 0x39, 0x2e, 0x4d, 0x98, 0xff, 0xe4, 0xbb, 0x84, 0x51, 0x9e, 0xaf, 0x3a, 0xfe, 0x2e,
-	0xdf, 0xe6, 0xae, 0xed, 0x36, 0xd1, 0x35, 0xa8, 0x7e, 0xc8, 0x68, 0xf2, 0x4c, 0x17, 0x3e, 0x61,
-	0xb4, 0x52, 0x28, 0xb6, 0xc5, 0x6e, 0x1d, 0x9f, 0x94, 0xf7, 0xcb, 0xa5, 0xef, 0x58, 0xbe, 0xff,
-	0x35, 0x2d, 0x3f, 0xf8, 0x1c, 0xcb, 0x0f, 0xbf, 0xcc, 0x72, 0xa4, 0x80, 0xe8, 0x26, 0xc1, 0x26,
-	0xbb, 0xd9, 0x52, 0xfb, 0x57, 0x00, 0x29, 0x73, 0x6c, 0x9c, 0x24, 0x51, 0x82, 0x7e, 0x07, 0xa0,
-	0xd9, 0x22, 0x9f, 0xa8, 0xfc, 0x15, 0x38, 0xab, 0x9e, 0xb2, 0x25, 0xf7, 0xf8, 0x2f, 0x9f, 0x26,
-	0x89, 0x16, 0x4b, 0x6d, 0x06, 0xd2, 0x16, 0x47, 0x08, 0x9a, 0x86, 0x69, 0x8f, 0xb1, 0xa9, 0x4f,
-	0x9d, 0x31, 0xc6, 0xf7, 0x38, 0x0f, 0xb9, 0x79, 0xef, 0xd8, 0x53, 0x4b, 0x11, 0xd0, 0x4f, 0xf0,
-	0xa3, 0x65, 0xdc, 0x9a, 0xba, 0x3d, 0xc7, 0x63, 0x47, 0x9f, 0xde, 0xde, 0x63, 0xc3, 0x9e, 0xdc,
-	0x39, 0x73, 0x53, 0x7f, 0xd0, 0x8d, 0xa9, 0x3e, 0x9c, 0x8e, 0x95, 0x9a, 0xf6, 0xbf, 0x00, 0xdf,
-	0xde, 0xd0, 0x17, 0xdf, 0xa5, 0xd9, 0xd1, 0xc5, 0xab, 0x75, 0x0d, 0x92, 0x5b, 0x8c, 0x06, 0x9f,
-	0x01, 0xf9, 0xb5, 0x1d, 0x95, 0xe9, 0xc1, 0x3b, 0x36, 0xba, 0x84, 0xa3, 0x64, 0x13, 0x39, 0x1e,
-	0x70, 0x79, 0xf0, 0xfd, 0xdb, 0xca, 0x22, 0x94, 0x78, 0xcb, 0x45, 0xbf, 0xc0, 0x01, 0xef, 0x93,
-	0x07, 0x5e, 0x1e, 0x9c, 0xbc, 0xe3, 0x0b, 0xce, 0x59, 0xe7, 0x7f, 0x02, 0x7a, 0x9b, 0x09, 0xd4,
-	0x02, 0x79, 0x6e, 0x5a, 0xb3, 0xf1, 0xc8, 0xf8, 0xc3, 0x18, 0xdf, 0x28, 0x7b, 0xe8, 0x18, 0x5e,
-	0xc7, 0x44, 0x11, 0xb2, 0x47, 0xa3, 0x00, 0x2d, 0x4b, 0xa9, 0x9d, 0x77, 0xa0, 0x51, 0xb9, 0x5d,
-	0x74, 0x04, 0xfc, 0x7e, 0x73, 0x3f, 0xad, 0x89, 0x3e, 0xb8, 0xb8, 0x54, 0x84, 0x61, 0x6d, 0x22,
-	0x7e, 0x0c, 0x00, 0x00, 0xff, 0xff, 0xfb, 0x9d, 0x75, 0x08, 0x01, 0x06, 0x00, 0x00,
+func init() { proto.RegisterFile("cast_channel.proto", fileDescriptor_cast_channel_5d337c104c7f7f60) }
+
+var fileDescriptor_cast_channel_5d337c104c7f7f60 = []byte{
+	// 784 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xb4, 0x54, 0xdd, 0x6a, 0xe4, 0x36,
+	0x18, 0x8d, 0x67, 0x92, 0x74, 0xfc, 0xcd, 0x9f, 0xab, 0xa5, 0xc4, 0x74, 0x0b, 0xf5, 0x4e, 0x09,
+	0xcc, 0x2e, 0x34, 0x74, 0xa6, 0x6c, 0xd8, 0xed, 0x55, 0x9d, 0xd9, 0x69, 0xc6, 0xed, 0xac, 0x93,
+	0xca, 0x4e, 0xa0, 0x57, 0x42, 0x6b, 0x2b, 0x63, 0x81, 0x23, 0x1b, 0x5b, 0x13, 0xc8, 0x6d, 0x9f,
+	0xa3, 0xd0, 0xa7, 0xe8, 0xfb, 0xf4, 0x51, 0x8a, 0xe5, 0x78, 0x6c, 0x27, 0x6c, 0x29, 0x85, 0xbd,
+	0x31, 0xd2, 0xd1, 0xf9, 0xac, 0xa3, 0xa3, 0xf3, 0x09, 0x50, 0x40, 0x73, 0x49, 0x82, 0x88, 0x0a,
+	0xc1, 0xe2, 0x93, 0x34, 0x4b, 0x64, 0x82, 0x06, 0x4d, 0x6c, 0xf2, 0xc7, 0x3e, 0xf4, 0x17, 0x34,
+	0x97, 0xef, 0x59, 0x9e, 0xd3, 0x0d, 0x43, 0x3e, 0x18, 0x8a, 0x16, 0x24, 0x31, 0xb9, 0x63, 0x59,
+	0xce, 0x13, 0x61, 0x6a, 0x56, 0x67, 0x3a, 0x9a, 0xbf, 0x3c, 0x69, 0xfd, 0xac, 0x51, 0x74, 0x72,
+	0xf9, 0x50, 0x71, 0x5d, 0x16, 0xe0, 0x71, 0xda, 0x06, 0xd0, 0x73, 0xd0, 0xf3, 0x64, 0x9b, 0x05,
+	0x8c, 0xf0, 0xd0, 0xec, 0x58, 0x9d, 0xa9, 0x8e, 0x7b, 0x25, 0xe0, 0x84, 0xe8, 0x18, 0x46, 0x21,
+	0xcb, 0x25, 0x17, 0x54, 0xf2, 0x44, 0x14, 0x8c, 0xae, 0x62, 0x0c, 0x1b, 0xa8, 0x13, 0xa2, 0xaf,
+	0x40, 0x17, 0xf4, 0x96, 0xe5, 0x29, 0x0d, 0x98, 0xb9, 0xaf, 0x18, 0x35, 0x80, 0x56, 0x30, 0x48,
+	0xe9, 0x7d, 0x9c, 0xd0, 0x90, 0xc8, 0xfb, 0x94, 0x99, 0x07, 0x4a, 0xf3, 0xf1, 0xbf, 0x68, 0x2e,
+	0xd9, 0xfe, 0x7d, 0xca, 0x70, 0x3f, 0xad, 0x27, 0xe8, 0x45, 0xfd, 0xa7, 0xad, 0xbc, 0x79, 0x63,
+	0x1e, 0x5a, 0xda, 0x54, 0xdf, 0x51, 0xae, 0xe4, 0xcd, 0x9b, 0x42, 0x71, 0x45, 0xf9, 0xc0, 0x05,
+	0xcd, 0xee, 0xcd, 0xcf, 0x2c, 0x6d, 0x3a, 0xc0, 0xc3, 0x07, 0xf4, 0x4c, 0x81, 0x85, 0xe2, 0x20,
+	0x11, 0x92, 0x8b, 0x2d, 0x0b, 0xcd, 0x9e, 0xa5, 0x4d, 0x7b, 0xb8, 0x06, 0xd0, 0x4b, 0x30, 0x32,
+	0x76, 0x4b, 0xb9, 0xe0, 0x62, 0x43, 0x62, 0x26, 0x36, 0x32, 0x32, 0x75, 0x4b, 0x9b, 0x0e, 0xf1,
+	0x78, 0x87, 0xaf, 0x15, 0x3c, 0xf9, 0x15, 0xc6, 0x8f, 0x2c, 0x46, 0x23, 0x80, 0x85, 0xed, 0xf9,
+	0xd7, 0x73, 0x32, 0x23, 0xdf, 0x19, 0x7b, 0xad, 0xf9, 0xcc, 0xd0, 0x5a, 0xf3, 0xb9, 0xd1, 0x69,
+	0xcd, 0xbf, 0x37, 0xba, 0x93, 0x63, 0xe8, 0x37, 0x1c, 0x40, 0x00, 0x87, 0x9e, 0x8f, 0x1d, 0xf7,
+	0xdc, 0xd8, 0x2b, 0xc6, 0x67, 0x8e, 0x6b, 0xe3, 0xdf, 0x0c, 0x6d, 0xf2, 0xb7, 0x06, 0x43, 0x7b,
+	0x2b, 0xa3, 0x45, 0x44, 0xe3, 0x42, 0x23, 0x43, 0x1f, 0xe0, 0x59, 0xce, 0x37, 0x82, 0xca, 0x6d,
+	0xc6, 0x08, 0x8d, 0x37, 0x49, 0xc6, 0x65, 0x74, 0x6b, 0x6a, 0x96, 0x36, 0x1d, 0xcd, 0xad, 0xb6,
+	0xdf, 0x5e, 0x45, 0xb4, 0x2b, 0xde, 0x0f, 0x63, 0xec, 0xd9, 0x9e, 0x67, 0x93, 0xcb, 0x5f, 0x16,
+	0xde, 0xec, 0x6e, 0xf6, 0x1a, 0xa3, 0xfc, 0x09, 0xa9, 0xb8, 0x82, 0x9c, 0x89, 0x90, 0x65, 0x44,
+	0x24, 0x22, 0x60, 0x66, 0x47, 0xb9, 0xdb, 0x2f, 0x31, 0xb7, 0x80, 0xd0, 0x0a, 0x46, 0x11, 0xcd,
+	0xa3, 0x86, 0x82, 0xae, 0x52, 0xf0, 0xbc, 0xad, 0x60, 0x45, 0xf3, 0xa8, 0xde, 0x7c, 0xdf, 0x5b,
+	0xd9, 0x33, 0x3c, 0x8c, 0x9a, 0xe0, 0xe4, 0xf7, 0x2e, 0x0c, 0x8a, 0x23, 0x62, 0x96, 0xa7, 0x89,
+	0xc8, 0x59, 0x71, 0x6d, 0x3b, 0x4d, 0x2a, 0xfb, 0x03, 0x5c, 0x03, 0xe8, 0x14, 0x8e, 0x82, 0x98,
+	0x33, 0x21, 0x09, 0xdd, 0xca, 0x88, 0x04, 0x2c, 0x93, 0xfc, 0x86, 0x07, 0x54, 0x32, 0x15, 0xec,
+	0x01, 0xfe, 0xa2, 0x5c, 0x56, 0xae, 0xd5, 0x8b, 0xe8, 0x2d, 0x98, 0x5c, 0x48, 0x96, 0xdd, 0xb2,
+	0x90, 0x53, 0xc9, 0x5a, 0x85, 0x5d, 0xab, 0x3b, 0x1d, 0xe0, 0xa3, 0xe6, 0x7a, 0xb3, 0xf4, 0x23,
+	0x96, 0xef, 0x7f, 0x4a, 0xcb, 0x0f, 0xfe, 0x8b, 0xe5, 0x87, 0xff, 0xcf, 0x72, 0x64, 0x40, 0x37,
+	0xc8, 0xe2, 0x87, 0xa6, 0x29, 0x86, 0x93, 0x3f, 0x35, 0xd0, 0x0b, 0xc7, 0x96, 0x59, 0x96, 0x64,
+	0xe8, 0x47, 0x00, 0x56, 0x0c, 0xca, 0x56, 0x2e, 0x9f, 0x9f, 0x17, 0xed, 0x5d, 0x76, 0xe4, 0x13,
+	0xf5, 0x55, 0x6d, 0xac, 0xb3, 0x6a, 0x38, 0xb9, 0x04, 0x7d, 0x87, 0x23, 0x04, 0x23, 0xc7, 0xf5,
+	0x97, 0xd8, 0xb5, 0xd7, 0x64, 0x89, 0xf1, 0x05, 0x2e, 0x43, 0xee, 0x5e, 0x10, 0x7f, 0xed, 0x19,
+	0x1a, 0xfa, 0x06, 0xbe, 0xf6, 0x9c, 0x73, 0xd7, 0xf6, 0xaf, 0xf0, 0x92, 0xd8, 0xeb, 0xf3, 0x0b,
+	0xec, 0xf8, 0xab, 0xf7, 0xe4, 0xca, 0xb5, 0xaf, 0x6d, 0x67, 0x6d, 0x9f, 0xad, 0x97, 0x46, 0x67,
+	0xf2, 0x97, 0x06, 0x9f, 0xbf, 0x63, 0x77, 0x3c, 0x60, 0xc5, 0xd6, 0xd5, 0x73, 0xf9, 0x16, 0xf4,
+	0xa0, 0x6a, 0x0d, 0xd5, 0x03, 0xfd, 0xc7, 0x76, 0xb4, 0xba, 0x07, 0xd7, 0x6c, 0x74, 0x0a, 0xbd,
+	0xec, 0x21, 0x72, 0x2a, 0xe0, 0xfd, 0xf9, 0x97, 0x4f, 0x2b, 0xab, 0x50, 0xe2, 0x1d, 0x17, 0x7d,
+	0x0b, 0x07, 0xea, 0x9c, 0x2a, 0xf0, 0xfd, 0xf9, 0xd1, 0x47, 0x7c, 0xc1, 0x25, 0xeb, 0xd5, 0xcf,
+	0x80, 0x9e, 0x66, 0x02, 0x8d, 0xa1, 0x7f, 0xe5, 0x7a, 0x97, 0xcb, 0x85, 0xf3, 0x93, 0xb3, 0x7c,
+	0x67, 0xec, 0xa1, 0x67, 0xf0, 0x38, 0x26, 0xe5, 0x23, 0x52, 0x81, 0x9e, 0x67, 0x74, 0x5e, 0x1d,
+	0xc3, 0xb0, 0x75, 0xbb, 0xa8, 0x07, 0xea, 0x7e, 0x4b, 0x3f, 0xbd, 0x95, 0x3d, 0x7f, 0x7d, 0x6a,
+	0x68, 0x67, 0x9d, 0x55, 0xf7, 0x9f, 0x01, 0x00, 0xfd, 0x89, 0xfe, 0xb7, 0x7a, 0x06, 0x00, 0x00,
 }
@@ -1,105 +1,612 @@
 package server
 
 import (
+	"crypto/tls"
 	"encoding/binary"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unicode/utf8"
 
 	"github.com/cretz/owncast/owncast/log"
 	"github.com/cretz/owncast/owncast/server/cast_channel"
 	"github.com/golang/protobuf/proto"
 )
 
+const (
+	PlatformReceiverID = "receiver-0"
+	PlatformSenderID   = "sender-0"
+	BroadcastID        = "*"
+)
+
+var ErrConnClosed = errors.New("Connection closed")
+var ErrSendQueueFull = errors.New("Send queue full")
+
+// ProtocolError is returned when the peer violates the framing or protocol, as opposed to an ordinary disconnect or
+// I/O failure which are returned wrapped as-is (e.g. io.EOF).
+type ProtocolError struct {
+	Reason string
+}
+
+func (p *ProtocolError) Error() string { return "Protocol violation: " + p.Reason }
+
+func IsProtocolError(err error) bool {
+	var protoErr *ProtocolError
+	return errors.As(err, &protoErr)
+}
+
+// SendQueueFullPolicy is what to do when a send is attempted while the conn's send queue is full
+type SendQueueFullPolicy int
+
+const (
+	// Wait for room up to the write timeout, closing the conn if there is still no room
+	SendQueueFullWait SendQueueFullPolicy = iota
+	// Close the conn immediately
+	SendQueueFullClose
+	// Drop the message and return ErrSendQueueFull, leaving the conn open
+	SendQueueFullDrop
+)
+
+// Conn is a single sender connection. All Send* methods are safe for concurrent use, messages are queued and
+// written in order by a single writer goroutine.
 type Conn struct {
-	conn          net.Conn
-	server        *Server
-	Authenticated bool
-	Connected     bool
+	conn        net.Conn
+	server      *Server
+	connectedAt time.Time
+	// Set to 1 once device auth succeeds, accessed atomically
+	authenticated int32
+
+	virtualConns     map[virtualConnKey]*VirtualConn
+	virtualConnsLock sync.RWMutex
+
+	sendQueue  chan []byte
+	closed     chan struct{}
+	closeOnce  sync.Once
+	writerDone chan struct{}
+	closeErr   error
+
+	// Unix nanos, accessed atomically
+	lastReceived int64
+	// Highest protocol version seen from the peer, accessed atomically
+	peerVersion int32
+	// Partially received chunked messages and their total size, only accessed by the reader
+	chunks     map[chunkKey]*cast_channel.CastMessage
+	chunksSize int
+
+	// Last ID assigned by Request, accessed atomically
+	lastRequestID int32
+	requests      map[requestKey]*pendingRequest
+	requestsLock  sync.Mutex
 }
 
+// VirtualConn is a connection between a local endpoint (e.g. receiver-0 or an app transport ID) and a remote sender
+// endpoint multiplexed over a single Conn.
+type VirtualConn struct {
+	LocalID     string
+	RemoteID    string
+	Type        VirtualConnType
+	ConnectedAt time.Time
+	// Empty if not given on CONNECT
+	UserAgent string
+	// Nil if not given on CONNECT
+	SenderInfo *SenderInfo
+}
+
+// VirtualConnType is the connType a sender gives on CONNECT
+type VirtualConnType int
+
+const (
+	// A sender actively using the app, the default
+	VirtualConnStrong VirtualConnType = iota
+	// A sender that is not in the foreground, e.g. a backgrounded mobile app
+	VirtualConnWeak
+	// A sender only watching status that should not be seen by the app's users, e.g. a notification
+	VirtualConnInvisible
+)
+
+func (v VirtualConnType) String() string {
+	switch v {
+	case VirtualConnStrong:
+		return "strong"
+	case VirtualConnWeak:
+		return "weak"
+	case VirtualConnInvisible:
+		return "invisible"
+	default:
+		return fmt.Sprintf("connType(%v)", int(v))
+	}
+}
+
+type virtualConnKey struct{ localID, remoteID string }
+
+type chunkKey struct{ sourceID, destinationID, namespace string }
+
+const maxSupportedProtocolVersion = cast_channel.CastMessage_CASTV2_1_3
+
 func (s *Server) Accept() (*Conn, error) {
-	if s.tlsListener == nil {
+	s.tlsListenerLock.RLock()
+	listener := s.tlsListener
+	s.tlsListenerLock.RUnlock()
+	if listener == nil {
 		return nil, fmt.Errorf("No listener")
 	}
 	log.Debugf("Waiting for connection")
-	conn, err := s.tlsListener.Accept()
+	conn, err := listener.Accept()
 	if err != nil {
 		return nil, err
 	}
-	return &Conn{conn: conn, server: s}, nil
+	return s.newConn(conn), nil
 }
 
-func (c *Conn) Close() error { return c.conn.Close() }
+func (s *Server) newConn(conn net.Conn) *Conn {
+	c := &Conn{
+		conn:         conn,
+		server:       s,
+		connectedAt:  time.Now(),
+		virtualConns: map[virtualConnKey]*VirtualConn{},
+		sendQueue:    make(chan []byte, s.sendQueueSize),
+		closed:       make(chan struct{}),
+		writerDone:   make(chan struct{}),
+		lastReceived: time.Now().UnixNano(),
+		chunks:       map[chunkKey]*cast_channel.CastMessage{},
+		requests:     map[requestKey]*pendingRequest{},
+	}
+	c.lastRequestID = initialRequestID()
+	go c.runWriter()
+	if s.heartbeatInterval > 0 {
+		go c.runHeartbeat()
+	}
+	return c
+}
 
-func (c *Conn) ReceiveMessage() (Message, error) {
-	castMsg, err := c.ReceiveCastMessage()
+// Close flushes already-queued messages (each bounded by the write timeout) and then closes the underlying conn.
+// It is safe to call multiple times.
+func (c *Conn) Close() error {
+	c.closeOnce.Do(func() { close(c.closed) })
+	<-c.writerDone
+	return c.closeErr
+}
+
+// abort closes without flushing the send queue
+func (c *Conn) abort() {
+	c.closeOnce.Do(func() { close(c.closed) })
+	c.conn.Close()
+}
+
+func (c *Conn) runWriter() {
+	defer close(c.writerDone)
+	defer func() { c.closeErr = c.conn.Close() }()
+	for {
+		select {
+		case frame := <-c.sendQueue:
+			if !c.write(frame) {
+				return
+			}
+		case <-c.closed:
+			// Flush what is already queued
+			for {
+				select {
+				case frame := <-c.sendQueue:
+					if !c.write(frame) {
+						return
+					}
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+// write returns false and marks the conn closed on failure
+func (c *Conn) write(frame []byte) bool {
+	err := c.conn.SetWriteDeadline(time.Now().Add(c.server.writeTimeout))
+	if err == nil {
+		_, err = c.conn.Write(frame)
+	}
 	if err != nil {
-		return nil, fmt.Errorf("Failed receiving message: %v", err)
-	} else if castMsg.GetProtocolVersion() != cast_channel.CastMessage_CASTV2_1_0 {
-		return nil, fmt.Errorf("Unrecognized version: %v", castMsg.GetProtocolVersion())
+		log.Debugf("Closing connection after failed write: %v", err)
+		c.closeOnce.Do(func() { close(c.closed) })
+		return false
+	}
+	return true
+}
+
+// runHeartbeat sends PING to the platform virtual conns every interval and aborts the conn once the max number of
+// intervals pass without receiving anything. Like a real device, any received message counts, not just PONG.
+func (c *Conn) runHeartbeat() {
+	ticker := time.NewTicker(c.server.heartbeatInterval)
+	defer ticker.Stop()
+	lastSeen := atomic.LoadInt64(&c.lastReceived)
+	missed := 0
+	for {
+		select {
+		case <-c.closed:
+			return
+		case <-ticker.C:
+		}
+		if seen := atomic.LoadInt64(&c.lastReceived); seen != lastSeen {
+			lastSeen = seen
+			missed = 0
+		} else if missed++; missed >= c.server.heartbeatMaxMissed {
+			log.Debugf("Missed %v heartbeats, closing connection", missed)
+			c.abort()
+			return
+		}
+		for _, vc := range c.VirtualConns() {
+			if vc.LocalID == PlatformReceiverID {
+				if err := c.SendPayload(vc.LocalID, vc.RemoteID, NamespaceHeartbeat, pingPayload); err != nil {
+					log.Debugf("Failed sending ping: %v", err)
+				}
+			}
+		}
+	}
+}
+
+func (c *Conn) enqueue(frame []byte) error {
+	select {
+	case <-c.closed:
+		return ErrConnClosed
+	default:
+	}
+	select {
+	case c.sendQueue <- frame:
+		return nil
+	default:
+	}
+	switch c.server.sendQueueFullPolicy {
+	case SendQueueFullDrop:
+		return ErrSendQueueFull
+	case SendQueueFullClose:
+		log.Debugf("Send queue full, closing connection")
+		c.abort()
+		return ErrSendQueueFull
+	default:
+		timer := time.NewTimer(c.server.writeTimeout)
+		defer timer.Stop()
+		select {
+		case c.sendQueue <- frame:
+			return nil
+		case <-c.closed:
+			return ErrConnClosed
+		case <-timer.C:
+			log.Debugf("Send queue still full after %v, closing connection", c.server.writeTimeout)
+			c.abort()
+			return ErrSendQueueFull
+		}
+	}
+}
+
+// RemoteAddr is the sender's address
+func (c *Conn) RemoteAddr() net.Addr { return c.conn.RemoteAddr() }
+
+// ConnectedAt is when the conn was accepted
+func (c *Conn) ConnectedAt() time.Time { return c.connectedAt }
+
+// Authenticated is whether the sender has completed device auth
+func (c *Conn) Authenticated() bool { return atomic.LoadInt32(&c.authenticated) == 1 }
+
+// TLSConnectionState returns the negotiated TLS parameters or false if the conn is not TLS
+func (c *Conn) TLSConnectionState() (tls.ConnectionState, bool) {
+	if tlsConn, ok := c.conn.(*tls.Conn); ok {
+		return tlsConn.ConnectionState(), true
+	}
+	return tls.ConnectionState{}, false
+}
+
+// Connect registers the virtual connection, returning the existing one if already connected
+func (c *Conn) Connect(localID string, remoteID string) *VirtualConn {
+	return c.connect(&VirtualConn{LocalID: localID, RemoteID: remoteID})
+}
+
+func (c *Conn) connect(vc *VirtualConn) *VirtualConn {
+	c.virtualConnsLock.Lock()
+	defer c.virtualConnsLock.Unlock()
+	key := virtualConnKey{vc.LocalID, vc.RemoteID}
+	if existing := c.virtualConns[key]; existing != nil {
+		return existing
 	}
-	return ParseMessage(castMsg)
+	vc.ConnectedAt = time.Now()
+	c.virtualConns[key] = vc
+	return vc
 }
 
+// Disconnect removes the virtual connection, returning it or nil if it was not connected
+func (c *Conn) Disconnect(localID string, remoteID string) *VirtualConn {
+	c.virtualConnsLock.Lock()
+	defer c.virtualConnsLock.Unlock()
+	key := virtualConnKey{localID, remoteID}
+	vc := c.virtualConns[key]
+	delete(c.virtualConns, key)
+	return vc
+}
+
+// VirtualConn returns the virtual connection or nil if not connected
+func (c *Conn) VirtualConn(localID string, remoteID string) *VirtualConn {
+	c.virtualConnsLock.RLock()
+	defer c.virtualConnsLock.RUnlock()
+	return c.virtualConns[virtualConnKey{localID, remoteID}]
+}
+
+// VirtualConns returns a snapshot of all connected virtual connections
+func (c *Conn) VirtualConns() []*VirtualConn {
+	c.virtualConnsLock.RLock()
+	defer c.virtualConnsLock.RUnlock()
+	ret := make([]*VirtualConn, 0, len(c.virtualConns))
+	for _, vc := range c.virtualConns {
+		ret = append(ret, vc)
+	}
+	return ret
+}
+
+// CloseVirtualConns sends CLOSE to every connected virtual connection and removes them. Since the receiver is the one
+// closing, senders leave their app sessions at once instead of being kept to rejoin.
+func (c *Conn) CloseVirtualConns() error { return c.closeVirtualConns(false) }
+
+// closeVirtualConns is CloseVirtualConns where lost is whether the conn ended without the senders closing
+func (c *Conn) closeVirtualConns(lost bool) (err error) {
+	c.virtualConnsLock.Lock()
+	vcs := c.virtualConns
+	c.virtualConns = map[virtualConnKey]*VirtualConn{}
+	c.virtualConnsLock.Unlock()
+	for _, vc := range vcs {
+		c.server.senderDisconnected(c, vc, lost)
+		if sendErr := c.SendPayload(vc.LocalID, vc.RemoteID, NamespaceConnection, closePayload); sendErr != nil {
+			err = sendErr
+		}
+	}
+	return
+}
+
+// ReceiveMessage receives the next full message, reassembling chunked messages, and parses it. This must not be
+// called concurrently.
+func (c *Conn) ReceiveMessage() (Message, error) {
+	for {
+		castMsg, err := c.ReceiveCastMessage()
+		if err != nil {
+			return nil, fmt.Errorf("Failed receiving message: %w", err)
+		}
+		version := castMsg.GetProtocolVersion()
+		if version < cast_channel.CastMessage_CASTV2_1_0 || version > maxSupportedProtocolVersion {
+			return nil, &ProtocolError{fmt.Sprintf("Unrecognized version: %v", version)}
+		}
+		if int32(version) > atomic.LoadInt32(&c.peerVersion) {
+			atomic.StoreInt32(&c.peerVersion, int32(version))
+		}
+		if castMsg, err = c.reassemble(castMsg); err != nil {
+			return nil, err
+		} else if castMsg != nil {
+			return c.server.ParseMessage(castMsg)
+		}
+	}
+}
+
+// Max partially received chunked messages per conn, one per source, destination and namespace
+const maxPendingChunkedMessages = 16
+
+// reassemble returns the full message once the last chunk is received or nil if more chunks are expected. All of the
+// conn's partial messages together must fit in the max chunked message size, so a peer can't buffer more by spreading
+// chunks over many sources or namespaces.
+func (c *Conn) reassemble(castMsg *cast_channel.CastMessage) (*cast_channel.CastMessage, error) {
+	key := chunkKey{castMsg.GetSourceId(), castMsg.GetDestinationId(), castMsg.GetNamespace()}
+	existing := c.chunks[key]
+	if existing == nil && !castMsg.GetContinued() {
+		return castMsg, nil
+	}
+	if existing == nil {
+		if len(c.chunks) >= maxPendingChunkedMessages {
+			return nil, &ProtocolError{fmt.Sprintf("More than %v partial chunked messages", maxPendingChunkedMessages)}
+		}
+		existing = &cast_channel.CastMessage{
+			ProtocolVersion: castMsg.ProtocolVersion,
+			SourceId:        castMsg.SourceId,
+			DestinationId:   castMsg.DestinationId,
+			Namespace:       castMsg.Namespace,
+			PayloadType:     castMsg.PayloadType,
+			PayloadUtf8:     new(string),
+		}
+		c.chunks[key] = existing
+	} else if existing.GetPayloadType() != castMsg.GetPayloadType() {
+		c.dropChunks(key)
+		return nil, &ProtocolError{"Chunk payload type changed mid-message"}
+	}
+	*existing.PayloadUtf8 += castMsg.GetPayloadUtf8()
+	existing.PayloadBinary = append(existing.PayloadBinary, castMsg.PayloadBinary...)
+	c.chunksSize += len(castMsg.GetPayloadUtf8()) + len(castMsg.PayloadBinary)
+	size := len(existing.GetPayloadUtf8()) + len(existing.PayloadBinary) + int(castMsg.GetRemainingLength())
+	if size > c.server.maxChunkedMessageSize {
+		c.dropChunks(key)
+		return nil, &ProtocolError{fmt.Sprintf("Chunked message size %v exceeds max of %v",
+			size, c.server.maxChunkedMessageSize)}
+	}
+	if total := c.chunksSize + int(castMsg.GetRemainingLength()); total > c.server.maxChunkedMessageSize {
+		c.dropChunks(key)
+		return nil, &ProtocolError{fmt.Sprintf("Partial chunked messages size %v exceeds max of %v",
+			total, c.server.maxChunkedMessageSize)}
+	}
+	if castMsg.GetContinued() {
+		return nil, nil
+	}
+	c.dropChunks(key)
+	if existing.GetPayloadType() == cast_channel.CastMessage_BINARY {
+		existing.PayloadUtf8 = nil
+	}
+	return existing, nil
+}
+
+func (c *Conn) dropChunks(key chunkKey) {
+	if existing := c.chunks[key]; existing != nil {
+		c.chunksSize -= len(existing.GetPayloadUtf8()) + len(existing.PayloadBinary)
+		delete(c.chunks, key)
+	}
+}
+
+// ReceiveCastMessage waits up to the idle timeout for the next message and then up to the read timeout for the rest
+// of it. Oversized or malformed frames are a ProtocolError.
 func (c *Conn) ReceiveCastMessage() (*cast_channel.CastMessage, error) {
 	// Get msg size
+	if err := c.conn.SetReadDeadline(deadline(c.server.idleTimeout)); err != nil {
+		return nil, fmt.Errorf("Failed setting deadline: %w", err)
+	}
 	byts := make([]byte, 4)
 	if _, err := io.ReadFull(c.conn, byts); err != nil {
-		return nil, fmt.Errorf("Failed reading size: %v", err)
+		return nil, fmt.Errorf("Failed reading size: %w", err)
 	}
 	msgSize := binary.BigEndian.Uint32(byts)
+	if msgSize == 0 || msgSize > uint32(c.server.maxMessageSize) {
+		return nil, &ProtocolError{fmt.Sprintf("Message size %v not in range [1, %v]", msgSize, c.server.maxMessageSize)}
+	}
 	// Get actual message
+	if err := c.conn.SetReadDeadline(deadline(c.server.readTimeout)); err != nil {
+		return nil, fmt.Errorf("Failed setting deadline: %w", err)
+	}
 	byts = make([]byte, msgSize)
 	if _, err := io.ReadFull(c.conn, byts); err != nil {
-		return nil, fmt.Errorf("Unable to read msg: %v", err)
+		return nil, fmt.Errorf("Unable to read msg: %w", err)
 	}
 	var msg cast_channel.CastMessage
 	if err := proto.Unmarshal(byts, &msg); err != nil {
-		return nil, fmt.Errorf("Unable to unmarshal msg: %v", err)
+		return nil, &ProtocolError{fmt.Sprintf("Unable to unmarshal msg: %v", err)}
 	}
+	atomic.StoreInt64(&c.lastReceived, time.Now().UnixNano())
 	log.Debugf("Received message: %v", &msg)
 	return &msg, nil
 }
 
-func (c *Conn) SendPayload(namespace string, payload interface{}) error {
+func (c *Conn) SendPayload(sourceID string, destinationID string, namespace string, payload interface{}) error {
 	byts, err := json.Marshal(payload)
 	if err != nil {
 		return fmt.Errorf("Failed marshalling payload: %v", err)
 	}
-	return c.SendStringMessage(namespace, string(byts))
+	return c.SendStringMessage(sourceID, destinationID, namespace, string(byts))
+}
+
+// ReplyPayload sends the payload back to the virtual endpoint that sent the given message
+func (c *Conn) ReplyPayload(to *cast_channel.CastMessage, payload interface{}) error {
+	sourceID, destinationID := replyIDs(to)
+	return c.SendPayload(sourceID, destinationID, to.GetNamespace(), payload)
 }
 
+// SendMessage runs the outbound interceptors and then queues the message, splitting it into chunks if it is too large
+// and the peer supports chunking
 func (c *Conn) SendMessage(msg *cast_channel.CastMessage) error {
+	return c.server.interceptOutbound(c, msg, c.sendMessage)
+}
+
+func (c *Conn) sendMessage(msg *cast_channel.CastMessage) error {
 	log.Debugf("Sending message: %v", msg)
 	byts, err := proto.Marshal(msg)
 	if err != nil {
 		return fmt.Errorf("Unable to marshal cast message: %v", err)
 	}
-	sizeByts := make([]byte, 4)
-	binary.BigEndian.PutUint32(sizeByts, uint32(len(byts)))
-	if _, err = c.conn.Write(sizeByts); err != nil {
-		return fmt.Errorf("Unable to write size: %v", err)
+	if len(byts) > c.server.maxMessageSize {
+		return c.sendChunked(msg)
+	}
+	return c.sendFrame(byts)
+}
+
+// Generous room for everything but the payload
+const chunkOverhead = 512
+
+func (c *Conn) sendChunked(msg *cast_channel.CastMessage) error {
+	peerVersion := cast_channel.CastMessage_ProtocolVersion(atomic.LoadInt32(&c.peerVersion))
+	if peerVersion < cast_channel.CastMessage_CASTV2_1_1 {
+		return fmt.Errorf("Message exceeds max size of %v and peer does not support chunking", c.server.maxMessageSize)
+	}
+	overhead := len(msg.GetSourceId()) + len(msg.GetDestinationId()) + len(msg.GetNamespace()) + chunkOverhead
+	maxChunkSize := c.server.maxMessageSize - overhead
+	if maxChunkSize <= 0 {
+		return fmt.Errorf("Max message size of %v too small to chunk", c.server.maxMessageSize)
+	}
+	binary := msg.GetPayloadType() == cast_channel.CastMessage_BINARY
+	payload := []byte(msg.GetPayloadUtf8())
+	if binary {
+		payload = msg.PayloadBinary
+	}
+	payloads, err := splitChunks(payload, binary, maxChunkSize)
+	if err != nil {
+		return err
+	}
+	remaining := len(payload)
+	for i, chunkPayload := range payloads {
+		remaining -= len(chunkPayload)
+		chunk := &cast_channel.CastMessage{
+			ProtocolVersion: &peerVersion,
+			SourceId:        msg.SourceId,
+			DestinationId:   msg.DestinationId,
+			Namespace:       msg.Namespace,
+			PayloadType:     msg.PayloadType,
+			Continued:       proto.Bool(i < len(payloads)-1),
+			RemainingLength: proto.Uint32(uint32(remaining)),
+		}
+		if binary {
+			chunk.PayloadBinary = chunkPayload
+		} else {
+			chunk.PayloadUtf8 = proto.String(string(chunkPayload))
+		}
+		byts, err := proto.Marshal(chunk)
+		if err != nil {
+			return fmt.Errorf("Unable to marshal cast message chunk: %v", err)
+		} else if err = c.sendFrame(byts); err != nil {
+			return err
+		}
 	}
-	if _, err = c.conn.Write(byts); err != nil {
-		return fmt.Errorf("Unable to write bytes: %v", err)
+	return nil
+}
+
+// splitChunks splits the payload into pieces of at most maxChunkSize bytes. Unless binary, UTF-8 runes are not split
+// across pieces.
+func splitChunks(payload []byte, binary bool, maxChunkSize int) ([][]byte, error) {
+	var ret [][]byte
+	for len(payload) > 0 {
+		chunkSize := len(payload)
+		if chunkSize > maxChunkSize {
+			chunkSize = maxChunkSize
+			for !binary && chunkSize > 0 && !utf8.RuneStart(payload[chunkSize]) {
+				chunkSize--
+			}
+			if chunkSize == 0 {
+				return nil, fmt.Errorf("Max chunk size of %v too small for UTF-8 payload", maxChunkSize)
+			}
+		}
+		ret = append(ret, payload[:chunkSize])
+		payload = payload[chunkSize:]
+	}
+	return ret, nil
+}
+
+func (c *Conn) sendFrame(byts []byte) error {
+	// Size and message are written together so frames can never interleave
+	frame := make([]byte, 4+len(byts))
+	binary.BigEndian.PutUint32(frame, uint32(len(byts)))
+	copy(frame[4:], byts)
+	if err := c.enqueue(frame); err != nil {
+		return fmt.Errorf("Unable to send message: %w", err)
 	}
 	return nil
 }
 
-func (c *Conn) SendProtoMessage(namespace string, msg proto.Message) error {
+func (c *Conn) SendProtoMessage(sourceID string, destinationID string, namespace string, msg proto.Message) error {
 	byts, err := proto.Marshal(msg)
 	if err != nil {
 		return fmt.Errorf("Failed marshalling message: %v", err)
 	}
-	return c.SendBinaryMessage(namespace, byts)
+	return c.SendBinaryMessage(sourceID, destinationID, namespace, byts)
+}
+
+// ReplyProtoMessage sends the proto message back to the virtual endpoint that sent the given message
+func (c *Conn) ReplyProtoMessage(to *cast_channel.CastMessage, msg proto.Message) error {
+	sourceID, destinationID := replyIDs(to)
+	return c.SendProtoMessage(sourceID, destinationID, to.GetNamespace(), msg)
 }
 
-func (c *Conn) SendBinaryMessage(namespace string, msg []byte) error {
+func (c *Conn) SendBinaryMessage(sourceID string, destinationID string, namespace string, msg []byte) error {
 	version := cast_channel.CastMessage_CASTV2_1_0
-	sourceID := "receiver-0"
-	destinationID := "sender-0"
 	payloadType := cast_channel.CastMessage_BINARY
 	return c.SendMessage(&cast_channel.CastMessage{
 		ProtocolVersion: &version,
@@ -111,10 +618,8 @@ func (c *Conn) SendBinaryMessage(namespace string, msg []byte) error {
 	})
 }
 
-func (c *Conn) SendStringMessage(namespace string, msg string) error {
+func (c *Conn) SendStringMessage(sourceID string, destinationID string, namespace string, msg string) error {
 	version := cast_channel.CastMessage_CASTV2_1_0
-	sourceID := "receiver-0"
-	destinationID := "sender-0"
 	payloadType := cast_channel.CastMessage_STRING
 	return c.SendMessage(&cast_channel.CastMessage{
 		ProtocolVersion: &version,
@@ -125,3 +630,20 @@ func (c *Conn) SendStringMessage(namespace string, msg string) error {
 		PayloadUtf8:     &msg,
 	})
 }
+
+// deadline returns the zero time (i.e. no deadline) for a negative timeout
+func deadline(timeout time.Duration) time.Time {
+	if timeout < 0 {
+		return time.Time{}
+	}
+	return time.Now().Add(timeout)
+}
+
+func replyIDs(to *cast_channel.CastMessage) (sourceID string, destinationID string) {
+	// Messages sent to everyone are answered by the platform
+	sourceID = to.GetDestinationId()
+	if sourceID == BroadcastID {
+		sourceID = PlatformReceiverID
+	}
+	return sourceID, to.GetSourceId()
+}
@@ -0,0 +1,197 @@
+package server
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/cretz/owncast/owncast/server/cast_channel"
+	"github.com/golang/protobuf/proto"
+)
+
+func TestSplitChunks(t *testing.T) {
+	tests := []struct {
+		name         string
+		payload      string
+		binary       bool
+		maxChunkSize int
+		expected     []string
+		expectedErr  bool
+	}{
+		{name: "empty", payload: "", maxChunkSize: 3, expected: nil},
+		{name: "fits", payload: "abc", maxChunkSize: 3, expected: []string{"abc"}},
+		{name: "even", payload: "abcdef", maxChunkSize: 3, expected: []string{"abc", "def"}},
+		{name: "uneven", payload: "abcdefg", maxChunkSize: 3, expected: []string{"abc", "def", "g"}},
+		{name: "rune boundary", payload: "ab€cd", maxChunkSize: 3, expected: []string{"ab", "€", "cd"}},
+		{name: "rune fits exactly", payload: "€€", maxChunkSize: 3, expected: []string{"€", "€"}},
+		{name: "rune too large", payload: "a€", maxChunkSize: 2, expectedErr: true},
+		{name: "binary splits runes", payload: "a€", binary: true, maxChunkSize: 2,
+			expected: []string{"a\xe2", "\x82\xac"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			chunks, err := splitChunks([]byte(test.payload), test.binary, test.maxChunkSize)
+			if test.expectedErr {
+				if err == nil {
+					t.Fatalf("Expected error, got %q", chunks)
+				}
+				return
+			} else if err != nil {
+				t.Fatal(err)
+			}
+			if len(chunks) != len(test.expected) {
+				t.Fatalf("Expected %q, got %q", test.expected, chunks)
+			}
+			for i, chunk := range chunks {
+				if string(chunk) != test.expected[i] {
+					t.Fatalf("Expected %q, got %q", test.expected, chunks)
+				} else if len(chunk) > test.maxChunkSize {
+					t.Fatalf("Chunk %q exceeds %v", chunk, test.maxChunkSize)
+				} else if !test.binary && !utf8.Valid(chunk) {
+					t.Fatalf("Chunk %q is not valid UTF-8", chunk)
+				}
+			}
+		})
+	}
+}
+
+func newChunkTestConn(maxChunkedMessageSize int) *Conn {
+	return &Conn{
+		server: &Server{maxChunkedMessageSize: maxChunkedMessageSize},
+		chunks: map[chunkKey]*cast_channel.CastMessage{},
+	}
+}
+
+func chunkMessages(t *testing.T, payload []byte, binary bool, maxChunkSize int) []*cast_channel.CastMessage {
+	pieces, err := splitChunks(payload, binary, maxChunkSize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	payloadType := cast_channel.CastMessage_STRING
+	if binary {
+		payloadType = cast_channel.CastMessage_BINARY
+	}
+	var ret []*cast_channel.CastMessage
+	remaining := len(payload)
+	for i, piece := range pieces {
+		remaining -= len(piece)
+		msg := &cast_channel.CastMessage{
+			SourceId:        proto.String("sender-0"),
+			DestinationId:   proto.String("receiver-0"),
+			Namespace:       proto.String("urn:x-cast:test"),
+			PayloadType:     &payloadType,
+			Continued:       proto.Bool(i < len(pieces)-1),
+			RemainingLength: proto.Uint32(uint32(remaining)),
+		}
+		if binary {
+			msg.PayloadBinary = piece
+		} else {
+			msg.PayloadUtf8 = proto.String(string(piece))
+		}
+		ret = append(ret, msg)
+	}
+	return ret
+}
+
+func TestReassembleChunks(t *testing.T) {
+	payload := strings.Repeat("abc€", 100)
+	conn := newChunkTestConn(1024)
+	var full *cast_channel.CastMessage
+	for i, chunk := range chunkMessages(t, []byte(payload), false, 7) {
+		if full != nil {
+			t.Fatalf("Got full message before chunk %v", i)
+		}
+		var err error
+		if full, err = conn.reassemble(chunk); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if full == nil || full.GetPayloadUtf8() != payload {
+		t.Fatalf("Expected %q, got %v", payload, full)
+	} else if len(conn.chunks) != 0 {
+		t.Fatalf("Expected no pending chunks, got %v", len(conn.chunks))
+	} else if conn.chunksSize != 0 {
+		t.Fatalf("Expected no pending chunk size, got %v", conn.chunksSize)
+	}
+}
+
+func TestReassembleBinaryChunks(t *testing.T) {
+	payload := bytes.Repeat([]byte{0, 1, 2, 0xff}, 100)
+	conn := newChunkTestConn(1024)
+	var full *cast_channel.CastMessage
+	for _, chunk := range chunkMessages(t, payload, true, 9) {
+		var err error
+		if full, err = conn.reassemble(chunk); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if full == nil || !bytes.Equal(full.PayloadBinary, payload) || full.PayloadUtf8 != nil {
+		t.Fatalf("Expected %v, got %v", payload, full)
+	}
+}
+
+func TestReassembleUnchunked(t *testing.T) {
+	msg := &cast_channel.CastMessage{PayloadUtf8: proto.String("abc")}
+	full, err := newChunkTestConn(1).reassemble(msg)
+	if err != nil || full != msg {
+		t.Fatalf("Expected message as-is, got %v, %v", full, err)
+	}
+}
+
+func TestReassembleExceedsMax(t *testing.T) {
+	conn := newChunkTestConn(10)
+	chunks := chunkMessages(t, []byte(strings.Repeat("a", 20)), false, 5)
+	// The first chunk's remaining length already puts it over
+	_, err := conn.reassemble(chunks[0])
+	if !IsProtocolError(err) {
+		t.Fatalf("Expected protocol error, got %v", err)
+	} else if len(conn.chunks) != 0 {
+		t.Fatalf("Expected pending chunks to be dropped, got %v", len(conn.chunks))
+	}
+}
+
+func TestReassemblePayloadTypeChange(t *testing.T) {
+	conn := newChunkTestConn(1024)
+	chunks := chunkMessages(t, []byte("abcdef"), false, 3)
+	if _, err := conn.reassemble(chunks[0]); err != nil {
+		t.Fatal(err)
+	}
+	binaryType := cast_channel.CastMessage_BINARY
+	chunks[1].PayloadType = &binaryType
+	if _, err := conn.reassemble(chunks[1]); !IsProtocolError(err) {
+		t.Fatalf("Expected protocol error, got %v", err)
+	}
+}
+
+func TestReassembleTooManyPending(t *testing.T) {
+	conn := newChunkTestConn(1024 * 1024)
+	var err error
+	for i := 0; i < 2000 && err == nil; i++ {
+		chunk := chunkMessages(t, []byte("abcdef"), false, 3)[0]
+		chunk.SourceId = proto.String(fmt.Sprintf("sender-%v", i))
+		_, err = conn.reassemble(chunk)
+	}
+	if !IsProtocolError(err) {
+		t.Fatalf("Expected protocol error, got %v", err)
+	} else if len(conn.chunks) > maxPendingChunkedMessages {
+		t.Fatalf("Expected at most %v pending chunks, got %v", maxPendingChunkedMessages, len(conn.chunks))
+	}
+}
+
+func TestReassembleTotalExceedsMax(t *testing.T) {
+	conn := newChunkTestConn(1000)
+	// Each message fits alone, but the second doesn't fit alongside the first
+	first := chunkMessages(t, []byte(strings.Repeat("a", 900)), false, 300)
+	second := chunkMessages(t, []byte(strings.Repeat("b", 600)), false, 300)
+	second[0].SourceId = proto.String("sender-1")
+	for _, chunk := range first[:2] {
+		if _, err := conn.reassemble(chunk); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := conn.reassemble(second[0]); !IsProtocolError(err) {
+		t.Fatalf("Expected protocol error, got %v", err)
+	}
+}
@@ -0,0 +1,113 @@
+package server
+
+import (
+	"github.com/cretz/owncast/owncast/log"
+	"github.com/cretz/owncast/owncast/server/cast_channel"
+)
+
+// Handler parses and handles all messages for a namespace
+type Handler interface {
+	ParseMessage(castMessage *cast_channel.CastMessage) (Message, error)
+	HandleMessage(conn *Conn, msg Message) error
+}
+
+// HandlerFuncs is a Handler backed by functions. If ParseFunc is nil, every message is an UnknownMessage. If
+// HandleFunc is nil, messages that are MessageWithHandleDefault have HandleDefault invoked and others are ignored.
+type HandlerFuncs struct {
+	ParseFunc  func(castMessage *cast_channel.CastMessage) (Message, error)
+	HandleFunc func(conn *Conn, msg Message) error
+}
+
+func (h *HandlerFuncs) ParseMessage(castMessage *cast_channel.CastMessage) (Message, error) {
+	if h.ParseFunc == nil {
+		return &UnknownMessage{castMessage}, nil
+	}
+	return h.ParseFunc(castMessage)
+}
+
+func (h *HandlerFuncs) HandleMessage(conn *Conn, msg Message) error {
+	if h.HandleFunc == nil {
+		return HandleDefault(conn, msg)
+	}
+	return h.HandleFunc(conn, msg)
+}
+
+// HandleDefault invokes HandleDefault if the message is a MessageWithHandleDefault or ignores it otherwise
+func HandleDefault(conn *Conn, msg Message) error {
+	if msg, ok := msg.(MessageWithHandleDefault); ok {
+		return msg.HandleDefault(conn)
+	}
+	log.Debugf("Ignoring unhandled message: %v", msg.CastMessage())
+	return nil
+}
+
+// DefaultHandlers returns a new map of the built-in handlers keyed by namespace, decoding JSON payloads with the
+// registry
+func DefaultHandlers(payloads *PayloadRegistry) map[string]Handler {
+	// Binds the registry to a JSON payload parser
+	withPayloads := func(
+		parse func(*PayloadRegistry, *cast_channel.CastMessage) (Message, error),
+	) func(*cast_channel.CastMessage) (Message, error) {
+		return func(castMessage *cast_channel.CastMessage) (Message, error) { return parse(payloads, castMessage) }
+	}
+	return map[string]Handler{
+		NamespaceReceiver: &HandlerFuncs{
+			ParseFunc:  withPayloads(ParseReceiverMessage),
+			HandleFunc: HandleReceiverMessage,
+		},
+		NamespaceConnection: &HandlerFuncs{ParseFunc: withPayloads(ParseConnectionMessage)},
+		NamespaceDeviceAuth: &HandlerFuncs{ParseFunc: ParseDeviceAuthMessage},
+		NamespaceHeartbeat:  &HandlerFuncs{ParseFunc: withPayloads(ParseHeartbeatMessage)},
+		NamespaceDiscovery:  &HandlerFuncs{ParseFunc: withPayloads(ParseDiscoveryMessage)},
+		NamespaceMedia:      &HandlerFuncs{ParseFunc: withPayloads(ParseMediaMessage)},
+	}
+}
+
+// Handle sets the handler for the namespace, replacing any existing one. A nil handler removes it.
+func (s *Server) Handle(namespace string, handler Handler) {
+	s.handlersLock.Lock()
+	defer s.handlersLock.Unlock()
+	if handler == nil {
+		delete(s.handlers, namespace)
+	} else {
+		s.handlers[namespace] = handler
+	}
+}
+
+// Handler returns the handler for the namespace or nil if none
+func (s *Server) Handler(namespace string) Handler {
+	s.handlersLock.RLock()
+	defer s.handlersLock.RUnlock()
+	return s.handlers[namespace]
+}
+
+// ParseMessage parses using the namespace's handler or returns an UnknownMessage if there is no handler
+func (s *Server) ParseMessage(castMessage *cast_channel.CastMessage) (Message, error) {
+	if handler := s.Handler(castMessage.GetNamespace()); handler != nil {
+		return handler.ParseMessage(castMessage)
+	}
+	return &UnknownMessage{castMessage}, nil
+}
+
+// HandleMessage runs the interceptors and then completes a pending Conn.Request if the message is its reply. Otherwise
+// it routes messages sent to an app session's transport ID to the session, except for those on the connection
+// namespace, or handles using the namespace's handler or HandleDefault if there is no handler.
+func (s *Server) HandleMessage(conn *Conn, msg Message) error {
+	return s.interceptInbound(conn, msg, func(msg Message) error { return s.dispatchMessage(conn, msg) })
+}
+
+func (s *Server) dispatchMessage(conn *Conn, msg Message) error {
+	if conn.resolveRequest(msg) {
+		return nil
+	}
+	castMsg := msg.CastMessage()
+	if castMsg.GetNamespace() != NamespaceConnection {
+		if session := s.SessionByTransportID(castMsg.GetDestinationId()); session != nil {
+			return session.handleMessage(conn, msg)
+		}
+	}
+	if handler := s.Handler(castMsg.GetNamespace()); handler != nil {
+		return handler.HandleMessage(conn, msg)
+	}
+	return HandleDefault(conn, msg)
+}
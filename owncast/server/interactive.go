@@ -2,8 +2,12 @@ package server
 
 import (
 	"bufio"
+	"context"
+	"encoding/json"
 	"fmt"
 	"os"
+	"strings"
+	"sync/atomic"
 
 	"github.com/cretz/owncast/owncast/log"
 )
@@ -15,58 +19,117 @@ type UserInput interface {
 
 type stdioUserInput struct{ stdin *bufio.Reader }
 
+// ServerConnIndex is the conn index used for output that is not about a conn
+const ServerConnIndex = 0
+
+// CommandInput is a UserInput that can also be read for server commands
+type CommandInput interface {
+	UserInput
+	ReadCommand() (string, error)
+}
+
 func (*stdioUserInput) Printfln(connIndex int, format string, v ...interface{}) {
-	fmt.Printf("[conn-%v] %v\n", connIndex, fmt.Sprintf(format, v...))
+	fmt.Printf("%v %v\n", stdioPrefix(connIndex), fmt.Sprintf(format, v...))
 }
 
 func (s *stdioUserInput) Askfln(connIndex int, format string, v ...interface{}) (string, error) {
-	fmt.Printf("[conn-%v] %v", connIndex, fmt.Sprintf(format, v...))
+	fmt.Printf("%v %v", stdioPrefix(connIndex), fmt.Sprintf(format, v...))
 	return s.stdin.ReadString('\n')
 }
 
+func (s *stdioUserInput) ReadCommand() (string, error) { return s.stdin.ReadString('\n') }
+
+func stdioPrefix(connIndex int) string {
+	if connIndex == ServerConnIndex {
+		return "[server]"
+	}
+	return fmt.Sprintf("[conn-%v]", connIndex)
+}
+
 var StdioUserInput UserInput = &stdioUserInput{bufio.NewReader(os.Stdin)}
 
-// Closes server when done
-func RunServerInteractively(s *Server, input UserInput) error {
-	defer s.Close()
-	connIndexCounter := 0
-	for {
-		pconn, err := s.Accept()
-		if err != nil {
-			return err
-		}
-		connIndexCounter++
-		go func(connIndex int) {
-			if err := RunConnInteractively(connIndex, pconn, input); err != nil {
-				input.Printfln(connIndex, "Closed connection due to error: %v", err)
-			}
-		}(connIndexCounter)
+// Closes server when done, which is when the context is done or accepting fails. If the input is a CommandInput,
+// commands are read from it and run until it fails.
+func RunServerInteractively(ctx context.Context, s *Server, input UserInput) error {
+	if cmdInput, ok := input.(CommandInput); ok {
+		go runCommands(ctx, s, cmdInput)
 	}
+	var connIndexCounter int32
+	return s.ServeFunc(ctx, func(conn *Conn) error {
+		connIndex := int(atomic.AddInt32(&connIndexCounter, 1))
+		if err := RunConnInteractively(connIndex, conn, input); err != nil {
+			input.Printfln(connIndex, "Closed connection due to error: %v", err)
+		}
+		return nil
+	})
 }
 
 // Closes conn when done
 func RunConnInteractively(connIndex int, conn *Conn, input UserInput) error {
-	defer conn.Close()
-	defer func() {
-		if conn.Connected {
-			conn.SendPayload("urn:x-cast:com.google.cast.tp.connection", closePayload)
-		}
-	}()
-	for {
-		msg, err := conn.ReceiveMessage()
+	input.Printfln(connIndex, "Connected from %v", conn.RemoteAddr())
+	// TODO: interactive responses
+	return conn.server.ServeConn(conn)
+}
+
+const commandHelp = `Commands:
+  status               Show the receiver status
+  standby on|off       Set whether the display is in standby
+  input on|off         Set whether the display's input is on the receiver
+  help                 Show this help`
+
+func runCommands(ctx context.Context, s *Server, input CommandInput) {
+	for ctx.Err() == nil {
+		line, err := input.ReadCommand()
 		if err != nil {
-			return fmt.Errorf("Unable to parse message: %v", err)
+			log.Debugf("Stopped reading commands: %v", err)
+			return
 		}
-		switch msg := msg.(type) {
-		case MessageWithHandleDefault:
-			if err = msg.HandleDefault(conn); err != nil {
-				return fmt.Errorf("Failed handling message: %v", err)
+		if ctx.Err() != nil {
+			return
+		}
+		if fields := strings.Fields(line); len(fields) > 0 {
+			if err := runCommand(s, input, fields[0], fields[1:]); err != nil {
+				input.Printfln(ServerConnIndex, "%v", err)
 			}
-		default:
-			// TODO: interactive responses
-			log.Debugf("Ignoring unknown message: %v", msg)
 		}
 	}
 }
 
-var closePayload = &Payload{Type: "CLOSE"}
+func runCommand(s *Server, input UserInput, name string, args []string) error {
+	onOff := func() (bool, error) {
+		if len(args) == 1 && args[0] == "on" {
+			return true, nil
+		} else if len(args) == 1 && args[0] == "off" {
+			return false, nil
+		}
+		return false, fmt.Errorf("Expected 'on' or 'off'")
+	}
+	var status *ReceiverStatus
+	switch name {
+	case "status":
+		status = s.ReceiverStatus()
+	case "standby":
+		standBy, err := onOff()
+		if err != nil {
+			return err
+		}
+		status = s.SetStandBy(standBy)
+	case "input":
+		activeInput, err := onOff()
+		if err != nil {
+			return err
+		}
+		status = s.SetActiveInput(activeInput)
+	case "help":
+		input.Printfln(ServerConnIndex, "%v", commandHelp)
+		return nil
+	default:
+		return fmt.Errorf("Unknown command %v, try 'help'", name)
+	}
+	byts, err := json.Marshal(status)
+	if err != nil {
+		return err
+	}
+	input.Printfln(ServerConnIndex, "Receiver status: %s", byts)
+	return nil
+}
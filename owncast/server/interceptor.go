@@ -0,0 +1,83 @@
+package server
+
+import (
+	"github.com/cretz/owncast/owncast/server/cast_channel"
+)
+
+// InboundNext continues an inbound interceptor chain with the given message
+type InboundNext func(msg Message) error
+
+// OutboundNext continues an outbound interceptor chain with the given message
+type OutboundNext func(msg *cast_channel.CastMessage) error
+
+// Interceptor wraps message handling and sending. To pass a message, call next with it. To modify it, call next with
+// a different one. To drop it, return without calling next. To answer an inbound message directly, reply on the conn
+// and return without calling next. Returning an error closes the connection when inbound and fails the send when
+// outbound. Outbound interceptors must not send on the conn synchronously since that would re-enter the chain.
+type Interceptor interface {
+	InterceptInbound(conn *Conn, msg Message, next InboundNext) error
+	InterceptOutbound(conn *Conn, msg *cast_channel.CastMessage, next OutboundNext) error
+}
+
+// InterceptorFuncs is an Interceptor backed by functions. If either is nil, messages pass through it.
+type InterceptorFuncs struct {
+	InboundFunc  func(conn *Conn, msg Message, next InboundNext) error
+	OutboundFunc func(conn *Conn, msg *cast_channel.CastMessage, next OutboundNext) error
+}
+
+func (i *InterceptorFuncs) InterceptInbound(conn *Conn, msg Message, next InboundNext) error {
+	if i.InboundFunc == nil {
+		return next(msg)
+	}
+	return i.InboundFunc(conn, msg, next)
+}
+
+func (i *InterceptorFuncs) InterceptOutbound(conn *Conn, msg *cast_channel.CastMessage, next OutboundNext) error {
+	if i.OutboundFunc == nil {
+		return next(msg)
+	}
+	return i.OutboundFunc(conn, msg, next)
+}
+
+// AddInterceptor appends the interceptor so it runs after all existing ones
+func (s *Server) AddInterceptor(interceptor Interceptor) {
+	s.interceptorsLock.Lock()
+	defer s.interceptorsLock.Unlock()
+	// Copy on write so chains in progress keep their snapshot
+	interceptors := make([]Interceptor, len(s.interceptors), len(s.interceptors)+1)
+	copy(interceptors, s.interceptors)
+	s.interceptors = append(interceptors, interceptor)
+}
+
+// Interceptors returns the interceptors in the order they run
+func (s *Server) Interceptors() []Interceptor {
+	s.interceptorsLock.RLock()
+	defer s.interceptorsLock.RUnlock()
+	return s.interceptors
+}
+
+func (s *Server) interceptInbound(conn *Conn, msg Message, last InboundNext) error {
+	interceptors := s.Interceptors()
+	var next func(index int) InboundNext
+	next = func(index int) InboundNext {
+		if index >= len(interceptors) {
+			return last
+		}
+		return func(msg Message) error { return interceptors[index].InterceptInbound(conn, msg, next(index+1)) }
+	}
+	return next(0)(msg)
+}
+
+func (s *Server) interceptOutbound(conn *Conn, msg *cast_channel.CastMessage, last OutboundNext) error {
+	interceptors := s.Interceptors()
+	var next func(index int) OutboundNext
+	next = func(index int) OutboundNext {
+		if index >= len(interceptors) {
+			return last
+		}
+		return func(msg *cast_channel.CastMessage) error {
+			return interceptors[index].InterceptOutbound(conn, msg, next(index+1))
+		}
+	}
+	return next(0)(msg)
+}
@@ -5,6 +5,15 @@ import (
 	"github.com/cretz/owncast/owncast/server/cast_channel"
 )
 
+const (
+	NamespaceReceiver   = "urn:x-cast:com.google.cast.receiver"
+	NamespaceConnection = "urn:x-cast:com.google.cast.tp.connection"
+	NamespaceDeviceAuth = "urn:x-cast:com.google.cast.tp.deviceauth"
+	NamespaceHeartbeat  = "urn:x-cast:com.google.cast.tp.heartbeat"
+	NamespaceDiscovery  = "urn:x-cast:com.google.cast.receiver.discovery"
+	NamespaceMedia      = "urn:x-cast:com.google.cast.media"
+)
+
 type Message interface {
 	CastMessage() *cast_channel.CastMessage
 }
@@ -23,18 +32,3 @@ func (u *UnknownMessage) HandleDefault(conn *Conn) error {
 	log.Debugf("Ignoring unrecognized message: %v", u.castMessage)
 	return nil
 }
-
-func ParseMessage(msg *cast_channel.CastMessage) (Message, error) {
-	switch ns := msg.GetNamespace(); ns {
-	case "urn:x-cast:com.google.cast.receiver":
-		return ParseReceiverMessage(msg)
-	case "urn:x-cast:com.google.cast.tp.connection":
-		return ParseConnectionMessage(msg)
-	case "urn:x-cast:com.google.cast.tp.deviceauth":
-		return NewDeviceAuthMessage(msg)
-	case "urn:x-cast:com.google.cast.tp.heartbeat":
-		return NewPingMessage(msg)
-	default:
-		return &UnknownMessage{msg}, nil
-	}
-}
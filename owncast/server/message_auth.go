@@ -5,17 +5,30 @@ import (
 	"crypto/rand"
 	"crypto/rsa"
 	"fmt"
+	"sync/atomic"
 
 	"github.com/cretz/owncast/owncast/log"
 	"github.com/cretz/owncast/owncast/server/cast_channel"
 	"github.com/golang/protobuf/proto"
 )
 
+// DeviceAuthPolicy decides whether an auth challenge may be answered using the given algorithms. If it returns false,
+// the sender is sent a SIGNATURE_ALGORITHM_UNAVAILABLE error instead.
+type DeviceAuthPolicy func(conn *Conn, sigAlg cast_channel.SignatureAlgorithm, hashAlg cast_channel.HashAlgorithm) bool
+
 type DeviceAuthMessage struct {
 	cast_channel.DeviceAuthMessage
 	castMessage *cast_channel.CastMessage
 }
 
+func ParseDeviceAuthMessage(castMessage *cast_channel.CastMessage) (Message, error) {
+	msg, err := NewDeviceAuthMessage(castMessage)
+	if err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
 func NewDeviceAuthMessage(castMessage *cast_channel.CastMessage) (*DeviceAuthMessage, error) {
 	ret := &DeviceAuthMessage{castMessage: castMessage}
 	if err := proto.Unmarshal(castMessage.PayloadBinary, &ret.DeviceAuthMessage); err != nil {
@@ -29,7 +42,14 @@ func NewDeviceAuthMessage(castMessage *cast_channel.CastMessage) (*DeviceAuthMes
 
 func (d *DeviceAuthMessage) CastMessage() *cast_channel.CastMessage { return d.castMessage }
 
+// HandleDefault replies with an auth response or, if the challenge cannot be answered, an auth error. Only failing
+// to send is returned as an error.
 func (d *DeviceAuthMessage) HandleDefault(conn *Conn) error {
+	sigAlg, hashAlg := d.Challenge.GetSignatureAlgorithm(), d.Challenge.GetHashAlgorithm()
+	if policy := conn.server.deviceAuthPolicy; policy != nil && !policy(conn, sigAlg, hashAlg) {
+		log.Debugf("Auth policy disallowed signature algorithm %v and hash algorithm %v", sigAlg, hashAlg)
+		return d.replyError(conn, cast_channel.AuthError_SIGNATURE_ALGORITHM_UNAVAILABLE)
+	}
 	// Build auth response
 	authResp := &cast_channel.DeviceAuthMessage{
 		Response: &cast_channel.AuthResponse{
@@ -37,6 +57,8 @@ func (d *DeviceAuthMessage) HandleDefault(conn *Conn) error {
 			SignatureAlgorithm:    d.Challenge.SignatureAlgorithm,
 			SenderNonce:           d.Challenge.SenderNonce,
 			HashAlgorithm:         d.Challenge.HashAlgorithm,
+			// Senders don't signal whether they want this, so it is sent whenever we have one
+			Crl: conn.server.crl,
 		},
 	}
 	for _, inter := range conn.server.intermediateCACerts {
@@ -44,43 +66,53 @@ func (d *DeviceAuthMessage) HandleDefault(conn *Conn) error {
 	}
 	// Create hash
 	var hash crypto.Hash
-	switch d.Challenge.GetHashAlgorithm() {
+	switch hashAlg {
 	case cast_channel.HashAlgorithm_SHA1:
 		hash = crypto.SHA1
 	case cast_channel.HashAlgorithm_SHA256:
 		hash = crypto.SHA256
 	default:
-		return fmt.Errorf("Unrecognized hash algorithm: %v", d.Challenge.GetHashAlgorithm())
+		log.Debugf("Unrecognized hash algorithm: %v", hashAlg)
+		return d.replyError(conn, cast_channel.AuthError_SIGNATURE_ALGORITHM_UNAVAILABLE)
 	}
 	toSign := make([]byte, 0, len(d.Challenge.SenderNonce)+len(conn.server.peerCert.DERBytes))
 	toSign = append(toSign, d.Challenge.SenderNonce...)
 	toSign = append(toSign, conn.server.peerCert.DERBytes...)
 	hasher := hash.New()
 	if _, err := hasher.Write(toSign); err != nil {
-		return fmt.Errorf("Failed hashing: %v", err)
+		log.Debugf("Failed hashing: %v", err)
+		return d.replyError(conn, cast_channel.AuthError_INTERNAL_ERROR)
 	}
 	hashed := hasher.Sum(nil)
 	// Do the signature
 	var err error
-	switch d.Challenge.GetSignatureAlgorithm() {
+	switch sigAlg {
 	case cast_channel.SignatureAlgorithm_RSASSA_PKCS1v15:
 		authResp.Response.Signature, err = rsa.SignPKCS1v15(rand.Reader, conn.server.authCert.PrivKey, hash, hashed)
-		if err != nil {
-			return fmt.Errorf("Failed signing: %v", err)
-		}
 	case cast_channel.SignatureAlgorithm_RSASSA_PSS:
 		authResp.Response.Signature, err = rsa.SignPSS(rand.Reader, conn.server.authCert.PrivKey, hash, hashed, nil)
-		if err != nil {
-			return fmt.Errorf("Failed signing: %v", err)
-		}
 	default:
-		return fmt.Errorf("Unknown sig algo: %v", d.Challenge.GetSignatureAlgorithm())
+		log.Debugf("Unknown sig algo: %v", sigAlg)
+		return d.replyError(conn, cast_channel.AuthError_SIGNATURE_ALGORITHM_UNAVAILABLE)
+	}
+	if err != nil {
+		log.Debugf("Failed signing: %v", err)
+		return d.replyError(conn, cast_channel.AuthError_INTERNAL_ERROR)
 	}
 	// Send off the auth request
 	log.Debugf("Sending auth response: %v", authResp)
-	if err = conn.SendProtoMessage(d.castMessage.GetNamespace(), authResp); err != nil {
+	if err = conn.ReplyProtoMessage(d.castMessage, authResp); err != nil {
 		return fmt.Errorf("Failed sending auth message: %v", err)
 	}
-	conn.Authenticated = true
+	atomic.StoreInt32(&conn.authenticated, 1)
+	return nil
+}
+
+func (d *DeviceAuthMessage) replyError(conn *Conn, errorType cast_channel.AuthError_ErrorType) error {
+	authErr := &cast_channel.DeviceAuthMessage{Error: &cast_channel.AuthError{ErrorType: &errorType}}
+	log.Debugf("Sending auth error: %v", authErr)
+	if err := conn.ReplyProtoMessage(d.castMessage, authErr); err != nil {
+		return fmt.Errorf("Failed sending auth error: %v", err)
+	}
 	return nil
 }
@@ -1,23 +1,25 @@
 package server
 
 import (
-	"encoding/json"
 	"fmt"
 
 	"github.com/cretz/owncast/owncast/log"
 	"github.com/cretz/owncast/owncast/server/cast_channel"
 )
 
-func ParseConnectionMessage(castMessage *cast_channel.CastMessage) (Message, error) {
-	connRet := &ConnectMessage{castMessage: castMessage}
-	if err := connRet.UnmarshalPayload(castMessage); err != nil {
-		return nil, fmt.Errorf("Unable to get payload: %v", err)
-	} else if connRet.Type != "CONNECT" {
+func ParseConnectionMessage(payloads *PayloadRegistry, castMessage *cast_channel.CastMessage) (Message, error) {
+	payload, err := payloads.Decode(castMessage)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to get payload: %w", err)
+	}
+	switch payload := payload.(type) {
+	case *ConnectPayload:
+		return NewConnectMessage(payload, castMessage)
+	case *ClosePayload:
+		return NewCloseMessage(payload, castMessage)
+	default:
 		return &UnknownMessage{castMessage}, nil
-	} else if err := json.Unmarshal([]byte(connRet.JSON), &connRet.ConnectPayload); err != nil {
-		return nil, fmt.Errorf("Unable to parse payload: %v", err)
 	}
-	return connRet, nil
 }
 
 type ConnectMessage struct {
@@ -25,20 +27,48 @@ type ConnectMessage struct {
 	castMessage *cast_channel.CastMessage
 }
 
-func NewConnectMessage(castMessage *cast_channel.CastMessage) (*ConnectMessage, error) {
-	ret := &ConnectMessage{castMessage: castMessage}
-	if err := ret.UnmarshalPayload(castMessage); err != nil {
-		return nil, fmt.Errorf("Unable to get payload: %v", err)
-	} else if err := json.Unmarshal([]byte(ret.JSON), &ret.ConnectPayload); err != nil {
-		return nil, fmt.Errorf("Unable to parse payload: %v", err)
-	}
-	return ret, nil
+func NewConnectMessage(payload *ConnectPayload, castMessage *cast_channel.CastMessage) (*ConnectMessage, error) {
+	return &ConnectMessage{ConnectPayload: *payload, castMessage: castMessage}, nil
 }
 
 func (c *ConnectMessage) CastMessage() *cast_channel.CastMessage { return c.castMessage }
 
 func (c *ConnectMessage) HandleDefault(conn *Conn) error {
-	log.Debugf("Client connected, sender info: %v", c.SenderInfo)
-	conn.Connected = true
+	log.Debugf("Client %v connected to %v, conn type: %v, sender info: %v",
+		c.castMessage.GetSourceId(), c.castMessage.GetDestinationId(), c.ConnType, c.SenderInfo)
+	vc := &VirtualConn{
+		LocalID:    c.castMessage.GetDestinationId(),
+		RemoteID:   c.castMessage.GetSourceId(),
+		UserAgent:  c.UserAgent,
+		SenderInfo: c.SenderInfo,
+	}
+	if c.ConnType != nil {
+		vc.Type = *c.ConnType
+	}
+	vc = conn.connect(vc)
+	conn.server.senderConnected(conn, vc)
 	return nil
 }
+
+type CloseMessage struct {
+	ClosePayload
+	castMessage *cast_channel.CastMessage
+}
+
+func NewCloseMessage(payload *ClosePayload, castMessage *cast_channel.CastMessage) (*CloseMessage, error) {
+	return &CloseMessage{ClosePayload: *payload, castMessage: castMessage}, nil
+}
+
+func (c *CloseMessage) CastMessage() *cast_channel.CastMessage { return c.castMessage }
+
+func (c *CloseMessage) HandleDefault(conn *Conn) error {
+	log.Debugf("Client %v closed connection to %v", c.castMessage.GetSourceId(), c.castMessage.GetDestinationId())
+	if vc := conn.Disconnect(c.castMessage.GetDestinationId(), c.castMessage.GetSourceId()); vc == nil {
+		log.Debugf("Closed connection was not connected")
+	} else {
+		conn.server.senderDisconnected(conn, vc, false)
+	}
+	return nil
+}
+
+var closePayload = &ClosePayload{Payload{Type: "CLOSE"}}
@@ -0,0 +1,60 @@
+package server
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/cretz/owncast/owncast/log"
+	"github.com/cretz/owncast/owncast/server/cast_channel"
+)
+
+func ParseDiscoveryMessage(payloads *PayloadRegistry, castMessage *cast_channel.CastMessage) (Message, error) {
+	payload, err := payloads.Decode(castMessage)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to get payload: %w", err)
+	}
+	switch payload := payload.(type) {
+	case *GetDeviceInfoPayload:
+		return NewGetDeviceInfoMessage(payload, castMessage)
+	default:
+		return &UnknownMessage{castMessage}, nil
+	}
+}
+
+type GetDeviceInfoMessage struct {
+	GetDeviceInfoPayload
+	castMessage *cast_channel.CastMessage
+}
+
+func NewGetDeviceInfoMessage(
+	payload *GetDeviceInfoPayload,
+	castMessage *cast_channel.CastMessage,
+) (*GetDeviceInfoMessage, error) {
+	return &GetDeviceInfoMessage{GetDeviceInfoPayload: *payload, castMessage: castMessage}, nil
+}
+
+func (g *GetDeviceInfoMessage) CastMessage() *cast_channel.CastMessage { return g.castMessage }
+
+// HandleDefault answers with DEVICE_INFO built from the server's BroadcastText
+func (g *GetDeviceInfoMessage) HandleDefault(conn *Conn) error {
+	log.Debugf("Got device info request: %v", g.GetDeviceInfoPayload)
+	return conn.ReplyPayload(g.castMessage, conn.server.deviceInfo(g.RequestID))
+}
+
+func (s *Server) deviceInfo(requestID *int) *DeviceInfoPayload {
+	ret := &DeviceInfoPayload{
+		Payload:       Payload{Type: "DEVICE_INFO", RequestID: requestID},
+		DeviceID:      s.broadcastText["id"],
+		FriendlyName:  s.broadcastText["fn"],
+		DeviceModel:   s.broadcastText["md"],
+		DeviceIconURL: s.broadcastText["ic"],
+	}
+	if ca, ok := s.broadcastText["ca"]; ok {
+		capabilities, err := strconv.Atoi(ca)
+		if err != nil {
+			log.Debugf("Invalid capabilities %q in broadcast text: %v", ca, err)
+		}
+		ret.DeviceCapabilities = DeviceCapability(capabilities)
+	}
+	return ret
+}
@@ -3,28 +3,56 @@ package server
 import (
 	"fmt"
 
+	"github.com/cretz/owncast/owncast/log"
 	"github.com/cretz/owncast/owncast/server/cast_channel"
 )
 
+func ParseHeartbeatMessage(payloads *PayloadRegistry, castMessage *cast_channel.CastMessage) (Message, error) {
+	payload, err := payloads.Decode(castMessage)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to get payload: %w", err)
+	}
+	switch payload := payload.(type) {
+	case *PingPayload:
+		return NewPingMessage(payload, castMessage)
+	case *PongPayload:
+		return NewPongMessage(payload, castMessage)
+	default:
+		return &UnknownMessage{castMessage}, nil
+	}
+}
+
 type PingMessage struct {
-	Payload
+	PingPayload
 	castMessage *cast_channel.CastMessage
 }
 
-func NewPingMessage(castMessage *cast_channel.CastMessage) (*PingMessage, error) {
-	ret := &PingMessage{castMessage: castMessage}
-	if err := ret.UnmarshalPayload(castMessage); err != nil {
-		return nil, fmt.Errorf("Unable to get payload: %v", err)
-	} else if ret.Type != "PING" {
-		return nil, fmt.Errorf("Expected ping, got %v", ret.Type)
-	}
-	return ret, nil
+func NewPingMessage(payload *PingPayload, castMessage *cast_channel.CastMessage) (*PingMessage, error) {
+	return &PingMessage{PingPayload: *payload, castMessage: castMessage}, nil
 }
 
 func (p *PingMessage) CastMessage() *cast_channel.CastMessage { return p.castMessage }
 
-var pongPayload = &Payload{Type: "PONG"}
+var pingPayload = &PingPayload{Payload{Type: "PING"}}
+var pongPayload = &PongPayload{Payload{Type: "PONG"}}
 
 func (p *PingMessage) HandleDefault(conn *Conn) error {
-	return conn.SendPayload(p.castMessage.GetNamespace(), pongPayload)
+	return conn.ReplyPayload(p.castMessage, pongPayload)
+}
+
+type PongMessage struct {
+	PongPayload
+	castMessage *cast_channel.CastMessage
+}
+
+func NewPongMessage(payload *PongPayload, castMessage *cast_channel.CastMessage) (*PongMessage, error) {
+	return &PongMessage{PongPayload: *payload, castMessage: castMessage}, nil
+}
+
+func (p *PongMessage) CastMessage() *cast_channel.CastMessage { return p.castMessage }
+
+func (p *PongMessage) HandleDefault(conn *Conn) error {
+	// Receipt is already recorded by the conn, nothing else to do
+	log.Debugf("Got pong from %v", p.castMessage.GetSourceId())
+	return nil
 }
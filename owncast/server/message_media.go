@@ -0,0 +1,26 @@
+package server
+
+import (
+	"github.com/cretz/owncast/owncast/log"
+	"github.com/cretz/owncast/owncast/server/cast_channel"
+)
+
+// ParseMediaMessage decodes media messages into a MediaMessage. Media is handled by the launched app, so a payload
+// that cannot be decoded is left as an UnknownMessage for the app to answer instead of failing the conn.
+func ParseMediaMessage(payloads *PayloadRegistry, castMessage *cast_channel.CastMessage) (Message, error) {
+	payload, err := payloads.Decode(castMessage)
+	if err != nil {
+		log.Debugf("Leaving undecodable media message to the app: %v", err)
+		return &UnknownMessage{castMessage}, nil
+	}
+	return &MediaMessage{TypedPayload: payload, castMessage: castMessage}, nil
+}
+
+// MediaMessage is a media request or response. The payload is the struct registered in the server's payload registry,
+// e.g. a *MediaLoadPayload for LOAD, or a *Payload for unregistered types.
+type MediaMessage struct {
+	TypedPayload
+	castMessage *cast_channel.CastMessage
+}
+
+func (m *MediaMessage) CastMessage() *cast_channel.CastMessage { return m.castMessage }
@@ -1,28 +1,76 @@
 package server
 
 import (
-	"encoding/json"
 	"fmt"
 
 	"github.com/cretz/owncast/owncast/log"
 	"github.com/cretz/owncast/owncast/server/cast_channel"
 )
 
-func ParseReceiverMessage(castMessage *cast_channel.CastMessage) (Message, error) {
-	var payload Payload
-	if err := payload.UnmarshalPayload(castMessage); err != nil {
-		return nil, fmt.Errorf("Unable to get payload: %v")
+// ParseReceiverMessage parses receiver requests. Unknown types, requests with invalid fields and payloads that aren't
+// JSON objects are returned as an InvalidReceiverRequestMessage, the latter without a type or request ID.
+func ParseReceiverMessage(payloads *PayloadRegistry, castMessage *cast_channel.CastMessage) (Message, error) {
+	payload, err := payloads.Decode(castMessage)
+	if payloadErr, ok := err.(*PayloadError); ok {
+		log.Debugf("Invalid receiver request: %v", err)
+		return &InvalidReceiverRequestMessage{
+			Payload:     Payload{Type: payloadErr.Type, RequestID: payloadErr.RequestID},
+			Reason:      ReasonInvalidCommand,
+			castMessage: castMessage,
+		}, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("Unable to get payload: %w", err)
 	}
-	switch payload.Type {
-	case "GET_APP_AVAILABILITY":
-		return NewGetAppAvailabilityMessage(&payload, castMessage)
-	case "GET_STATUS":
-		return NewGetStatusRequestMessage(&payload, castMessage)
-	case "LAUNCH":
-		return NewLaunchMessage(&payload, castMessage)
+	var msg Message
+	switch payload := payload.(type) {
+	case *GetAppAvailabilityRequestPayload:
+		msg, err = NewGetAppAvailabilityMessage(payload, castMessage)
+	case *GetReceiverStatusRequestPayload:
+		msg, err = NewGetStatusRequestMessage(payload, castMessage)
+	case *LaunchPayload:
+		msg, err = NewLaunchMessage(payload, castMessage)
+	case *StopPayload:
+		msg, err = NewStopMessage(payload, castMessage)
+	case *SetVolumePayload:
+		msg, err = NewSetVolumeMessage(payload, castMessage)
 	default:
-		return &UnknownMessage{castMessage}, nil
+		err = fmt.Errorf("Unknown type %v", payload.BasePayload().Type)
 	}
+	if err != nil {
+		log.Debugf("Invalid receiver request: %v", err)
+		return &InvalidReceiverRequestMessage{
+			Payload:     *payload.BasePayload(),
+			Reason:      ReasonInvalidCommand,
+			castMessage: castMessage,
+		}, nil
+	}
+	return msg, nil
+}
+
+// HandleReceiverMessage answers with the error from the server's ReceiverRequestPolicy if it gives one. Otherwise the
+// message is handled with HandleDefault.
+func HandleReceiverMessage(conn *Conn, msg Message) error {
+	if policy := conn.server.receiverRequestPolicy; policy != nil {
+		if recvErr := policy(conn, msg); recvErr != nil {
+			log.Debugf("Receiver request rejected by policy: %v", recvErr)
+			return recvErr.reply(conn, msg)
+		}
+	}
+	return HandleDefault(conn, msg)
+}
+
+// InvalidReceiverRequestMessage is a receiver request that is malformed, of unknown type or with invalid fields. By
+// default it is answered with INVALID_REQUEST.
+type InvalidReceiverRequestMessage struct {
+	Payload
+	Reason      string
+	castMessage *cast_channel.CastMessage
+}
+
+func (i *InvalidReceiverRequestMessage) CastMessage() *cast_channel.CastMessage { return i.castMessage }
+
+func (i *InvalidReceiverRequestMessage) HandleDefault(conn *Conn) error {
+	return (&ReceiverError{Type: ReceiverErrorInvalidRequest, Reason: i.Reason}).reply(conn, i)
 }
 
 type GetAppAvailabilityMessage struct {
@@ -31,15 +79,10 @@ type GetAppAvailabilityMessage struct {
 }
 
 func NewGetAppAvailabilityMessage(
-	payload *Payload,
+	payload *GetAppAvailabilityRequestPayload,
 	castMessage *cast_channel.CastMessage,
 ) (*GetAppAvailabilityMessage, error) {
-	ret := &GetAppAvailabilityMessage{castMessage: castMessage}
-	ret.GetAppAvailabilityRequestPayload.Payload = *payload
-	if err := json.Unmarshal([]byte(ret.JSON), &ret.GetAppAvailabilityRequestPayload); err != nil {
-		return nil, fmt.Errorf("Unable to parse payload: %v", err)
-	}
-	return ret, nil
+	return &GetAppAvailabilityMessage{GetAppAvailabilityRequestPayload: *payload, castMessage: castMessage}, nil
 }
 
 func (g *GetAppAvailabilityMessage) CastMessage() *cast_channel.CastMessage {
@@ -52,34 +95,29 @@ func (g *GetAppAvailabilityMessage) HandleDefault(conn *Conn) error {
 		Payload:      g.Payload,
 		Availability: make(map[AppID]AppAvailability, len(g.AppID)),
 	}
-	// Just say they're all available
 	for _, appID := range g.AppID {
-		resp.Availability[appID] = AppAvailable
+		resp.Availability[appID] = conn.server.apps.Availability(appID)
 	}
-	return conn.SendPayload(g.castMessage.GetNamespace(), resp)
+	return conn.ReplyPayload(g.castMessage, resp)
 }
 
 type GetStatusRequestMessage struct {
-	Payload
+	GetReceiverStatusRequestPayload
 	castMessage *cast_channel.CastMessage
 }
 
 func NewGetStatusRequestMessage(
-	payload *Payload,
+	payload *GetReceiverStatusRequestPayload,
 	castMessage *cast_channel.CastMessage,
 ) (*GetStatusRequestMessage, error) {
-	return &GetStatusRequestMessage{Payload: *payload, castMessage: castMessage}, nil
+	return &GetStatusRequestMessage{GetReceiverStatusRequestPayload: *payload, castMessage: castMessage}, nil
 }
 
 func (g *GetStatusRequestMessage) CastMessage() *cast_channel.CastMessage { return g.castMessage }
 
 func (g *GetStatusRequestMessage) HandleDefault(conn *Conn) error {
-	log.Debugf("Got receiver get-status request: %v", &g.Payload)
-	resp := &GetReceiverStatusResponsePayload{
-		Payload: Payload{Type: "RECEIVER_STATUS", RequestID: g.RequestID},
-		Status:  sampleReceiverStatus("CC1AD845"),
-	}
-	return conn.SendPayload(g.castMessage.GetNamespace(), resp)
+	log.Debugf("Got receiver get-status request: %v", g.GetReceiverStatusRequestPayload)
+	return replyReceiverStatus(conn, g.castMessage, g.RequestID, conn.server.ReceiverStatus())
 }
 
 type LaunchMessage struct {
@@ -87,46 +125,82 @@ type LaunchMessage struct {
 	castMessage *cast_channel.CastMessage
 }
 
-func NewLaunchMessage(payload *Payload, castMessage *cast_channel.CastMessage) (*LaunchMessage, error) {
-	ret := &LaunchMessage{castMessage: castMessage}
-	ret.LaunchPayload.Payload = *payload
-	if err := json.Unmarshal([]byte(ret.JSON), &ret.LaunchPayload); err != nil {
-		return nil, fmt.Errorf("Unable to parse payload: %v", err)
-	}
-	return ret, nil
+func NewLaunchMessage(payload *LaunchPayload, castMessage *cast_channel.CastMessage) (*LaunchMessage, error) {
+	return &LaunchMessage{LaunchPayload: *payload, castMessage: castMessage}, nil
 }
 
 func (l *LaunchMessage) CastMessage() *cast_channel.CastMessage { return l.castMessage }
 
 func (l *LaunchMessage) HandleDefault(conn *Conn) error {
 	log.Debugf("Got launch request: %v", l.LaunchPayload)
-	resp := &GetReceiverStatusResponsePayload{
-		Payload: l.Payload,
-		Status:  sampleReceiverStatus(l.AppID),
+	if l.AppID == "" {
+		return (&ReceiverError{Type: ReceiverErrorLaunch, Reason: ReasonBadParameter}).reply(conn, l)
 	}
-	return conn.SendPayload(l.castMessage.GetNamespace(), resp)
-}
-
-func sampleReceiverStatus(appID string) *ReceiverStatus {
-	// TODO: this came from https://github.com/thibauts/node-castv2#controlling-applications
-	return &ReceiverStatus{
-		Applications: []*ApplicationSession{
-			&ApplicationSession{
-				AppID:       appID,
-				DisplayName: "Default Media Receiver",
-				Namespaces: []string{
-					"urn:x-cast:com.google.cast.player.message",
-					"urn:x-cast:com.google.cast.media",
-				},
-				SessionID:   "7E2FF513-CDF6-9A91-2B28-3E3DE7BAC174",
-				StatusText:  "Ready To Cast",
-				TransportID: "web-5",
-			},
-		},
-		IsActiveInput: true,
-		Volume: &Volume{
-			Level: 1,
-			Muted: false,
-		},
+	_, err := conn.server.launchApp(l.AppID, conn, l.castMessage.GetSourceId())
+	if err == ErrAppUnavailable {
+		err = &ReceiverError{Type: ReceiverErrorLaunch, Reason: ReasonNotFound}
 	}
+	if err != nil {
+		log.Debugf("Failed launching %v: %v", l.AppID, err)
+		recvErr, ok := err.(*ReceiverError)
+		if !ok {
+			// App failures the app didn't describe have no documented reason
+			recvErr = &ReceiverError{Type: ReceiverErrorLaunch}
+		}
+		return recvErr.reply(conn, l)
+	}
+	return replyReceiverStatus(conn, l.castMessage, l.RequestID, conn.server.ReceiverStatus())
+}
+
+type StopMessage struct {
+	StopPayload
+	castMessage *cast_channel.CastMessage
+}
+
+func NewStopMessage(payload *StopPayload, castMessage *cast_channel.CastMessage) (*StopMessage, error) {
+	return &StopMessage{StopPayload: *payload, castMessage: castMessage}, nil
+}
+
+func (s *StopMessage) CastMessage() *cast_channel.CastMessage { return s.castMessage }
+
+func (s *StopMessage) HandleDefault(conn *Conn) error {
+	log.Debugf("Got stop request: %v", s.StopPayload)
+	if s.SessionID != "" && conn.server.Session(s.SessionID) == nil {
+		recvErr := &ReceiverError{Type: ReceiverErrorInvalidRequest, Reason: ReasonInvalidSessionID}
+		return recvErr.reply(conn, s)
+	}
+	// No session ID means stop whatever is running
+	for _, session := range conn.server.Sessions() {
+		if s.SessionID == "" || session.SessionID == s.SessionID {
+			conn.server.stopApp(session.SessionID, conn, s.castMessage.GetSourceId())
+		}
+	}
+	return replyReceiverStatus(conn, s.castMessage, s.RequestID, conn.server.ReceiverStatus())
+}
+
+type SetVolumeMessage struct {
+	SetVolumePayload
+	castMessage *cast_channel.CastMessage
+}
+
+func NewSetVolumeMessage(payload *SetVolumePayload, castMessage *cast_channel.CastMessage) (*SetVolumeMessage, error) {
+	if payload.Volume == nil {
+		return nil, fmt.Errorf("Missing volume")
+	}
+	return &SetVolumeMessage{SetVolumePayload: *payload, castMessage: castMessage}, nil
+}
+
+func (s *SetVolumeMessage) CastMessage() *cast_channel.CastMessage { return s.castMessage }
+
+func (s *SetVolumeMessage) HandleDefault(conn *Conn) error {
+	log.Debugf("Got set volume request: %v", s.Volume)
+	status := conn.server.setVolume(conn, s.castMessage.GetSourceId(), s.Volume)
+	return replyReceiverStatus(conn, s.castMessage, s.RequestID, status)
+}
+
+func replyReceiverStatus(conn *Conn, to *cast_channel.CastMessage, requestID *int, status *ReceiverStatus) error {
+	return conn.ReplyPayload(to, &GetReceiverStatusResponsePayload{
+		Payload: Payload{Type: "RECEIVER_STATUS", RequestID: requestID},
+		Status:  status,
+	})
 }
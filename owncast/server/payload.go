@@ -1,42 +1,113 @@
 package server
 
 import (
-	"encoding/json"
 	"fmt"
-
-	"github.com/cretz/owncast/owncast/server/cast_channel"
 )
 
 type Payload struct {
 	Type      string `json:"type"`
 	RequestID *int   `json:"requestId,omitempty"`
-	JSON      string `json:"-"`
 }
 
-func (p *Payload) UnmarshalPayload(msg *cast_channel.CastMessage) error {
-	if msg.PayloadUtf8 == nil {
-		return fmt.Errorf("Missing string payload")
+type ConnectPayload struct {
+	Payload
+	ConnType *VirtualConnType `json:"connType,omitempty"`
+	// Undocumented and usually empty
+	Origin     map[string]interface{} `json:"origin,omitempty"`
+	SenderInfo *SenderInfo            `json:"senderInfo,omitempty"`
+	UserAgent  string                 `json:"userAgent,omitempty"`
+}
+
+type ClosePayload struct {
+	Payload
+}
+
+type PingPayload struct {
+	Payload
+}
+
+type PongPayload struct {
+	Payload
+}
+
+// SenderInfo is what a sender says about itself on CONNECT
+type SenderInfo struct {
+	SDKType        int                  `json:"sdkType"`
+	Version        string               `json:"version,omitempty"`
+	BrowserVersion string               `json:"browserVersion,omitempty"`
+	Platform       SenderPlatform       `json:"platform"`
+	SystemVersion  string               `json:"systemVersion,omitempty"`
+	ConnectionType SenderConnectionType `json:"connectionType"`
+}
+
+type SenderPlatform int
+
+const (
+	SenderPlatformUnknown SenderPlatform = iota
+	SenderPlatformAndroid
+	SenderPlatformIOS
+	SenderPlatformWindows
+	SenderPlatformMac
+	SenderPlatformChromeOS
+	SenderPlatformLinux
+	SenderPlatformCast
+)
+
+func (s SenderPlatform) String() string {
+	switch s {
+	case SenderPlatformUnknown:
+		return "unknown"
+	case SenderPlatformAndroid:
+		return "Android"
+	case SenderPlatformIOS:
+		return "iOS"
+	case SenderPlatformWindows:
+		return "Windows"
+	case SenderPlatformMac:
+		return "Mac"
+	case SenderPlatformChromeOS:
+		return "Chrome OS"
+	case SenderPlatformLinux:
+		return "Linux"
+	case SenderPlatformCast:
+		return "Cast"
+	default:
+		return fmt.Sprintf("platform(%v)", int(s))
 	}
-	p.JSON = *msg.PayloadUtf8
-	if err := json.Unmarshal([]byte(p.JSON), p); err != nil {
-		return fmt.Errorf("Failed parsing JSON: %v", err)
+}
+
+type SenderConnectionType int
+
+const (
+	SenderConnectionTypeUnknown SenderConnectionType = iota
+	SenderConnectionTypeEthernet
+	SenderConnectionTypeWiFi
+)
+
+func (s SenderConnectionType) String() string {
+	switch s {
+	case SenderConnectionTypeUnknown:
+		return "unknown"
+	case SenderConnectionTypeEthernet:
+		return "ethernet"
+	case SenderConnectionTypeWiFi:
+		return "wifi"
+	default:
+		return fmt.Sprintf("connection(%v)", int(s))
 	}
-	return nil
 }
 
-type ConnectPayload struct {
-	Payload
-	ConnType   *int
-	Origin     map[string]interface{}
-	SenderInfo map[string]interface{}
-	UserAgent  string
+func (s *SenderInfo) String() string {
+	return fmt.Sprintf("SDK %v v%v on %v %v (browser %v, %v)",
+		s.SDKType, s.Version, s.Platform, s.SystemVersion, s.BrowserVersion, s.ConnectionType)
 }
 
 type AppID string
 
 const (
-	MirroringAppID      AppID = "0F5096E8"
-	AudioMirroringAppID AppID = "85CDB22F"
+	DefaultMediaReceiverAppID AppID = "CC1AD845"
+	MirroringAppID            AppID = "0F5096E8"
+	AudioMirroringAppID       AppID = "85CDB22F"
 )
 
 type GetAppAvailabilityRequestPayload struct {
@@ -56,6 +127,10 @@ type GetAppAvailabilityResponsePayload struct {
 	Availability map[AppID]AppAvailability `json:"availability"`
 }
 
+type GetReceiverStatusRequestPayload struct {
+	Payload
+}
+
 type GetReceiverStatusResponsePayload struct {
 	Payload
 	Status *ReceiverStatus `json:"status,omitempty"`
@@ -63,7 +138,8 @@ type GetReceiverStatusResponsePayload struct {
 
 type ReceiverStatus struct {
 	Applications  []*ApplicationSession `json:"applications"`
-	IsActiveInput bool                  `json:"isActiveInput,omitempty"`
+	IsActiveInput bool                  `json:"isActiveInput"`
+	IsStandBy     bool                  `json:"isStandBy"`
 	Volume        *Volume               `json:"volume,omitempty"`
 }
 
@@ -71,18 +147,80 @@ type ApplicationSession struct {
 	AppID       string   `json:"appId,omitempty"`
 	DisplayName string   `json:"displayName,omitempty"`
 	Namespaces  []string `json:"namespaces"`
+	IconURL     string   `json:"iconUrl,omitempty"`
 	SessionID   string   `json:"sessionId,omitempty"`
 	StatusText  string   `json:"statusText,omitempty"`
 	TransportID string   `json:"transportId,omitempty"`
+	// Whether a strong or weak sender is joined, invisible ones don't count
+	SenderConnected bool `json:"senderConnected"`
 }
 
 type Volume struct {
-	Level float64 `json:"level,omitempty"`
-	Muted bool    `json:"muted"`
+	Level        float64           `json:"level"`
+	Muted        bool              `json:"muted"`
+	ControlType  VolumeControlType `json:"controlType,omitempty"`
+	StepInterval float64           `json:"stepInterval,omitempty"`
 }
 
+// VolumeControlType is how the receiver's volume can be changed
+type VolumeControlType string
+
+const (
+	// The receiver's own volume can be changed, e.g. a TV
+	VolumeControlAttenuation VolumeControlType = "attenuation"
+	// The volume cannot be changed or muted, e.g. a device with only line out
+	VolumeControlFixed VolumeControlType = "fixed"
+	// The volume changes the system's master volume, e.g. a speaker
+	VolumeControlMaster VolumeControlType = "master"
+)
+
 type LaunchPayload struct {
 	Payload
 	AppID    string
 	Language string
 }
+
+type ReceiverErrorPayload struct {
+	Payload
+	Reason string `json:"reason,omitempty"`
+}
+
+type StopPayload struct {
+	Payload
+	SessionID string `json:"sessionId,omitempty"`
+}
+
+type SetVolumePayload struct {
+	Payload
+	Volume *VolumeRequest `json:"volume"`
+}
+
+// VolumeRequest has only the fields being changed set
+type VolumeRequest struct {
+	Level *float64 `json:"level,omitempty"`
+	Muted *bool    `json:"muted,omitempty"`
+}
+
+type GetDeviceInfoPayload struct {
+	Payload
+}
+
+type DeviceInfoPayload struct {
+	Payload
+	DeviceID           string           `json:"deviceId"`
+	FriendlyName       string           `json:"friendlyName"`
+	DeviceModel        string           `json:"deviceModel"`
+	DeviceCapabilities DeviceCapability `json:"deviceCapabilities"`
+	DeviceIconURL      string           `json:"deviceIconUrl,omitempty"`
+}
+
+// DeviceCapability is a bit set of what the device supports, advertised as "ca" in the mDNS text
+type DeviceCapability int
+
+const (
+	DeviceCapabilityVideoOut       DeviceCapability = 1 << 0
+	DeviceCapabilityVideoIn        DeviceCapability = 1 << 1
+	DeviceCapabilityAudioOut       DeviceCapability = 1 << 2
+	DeviceCapabilityAudioIn        DeviceCapability = 1 << 3
+	DeviceCapabilityMultizoneGroup DeviceCapability = 1 << 5
+)
@@ -0,0 +1,182 @@
+package server
+
+import (
+	"encoding/json"
+)
+
+// MediaInformation describes the media being played
+type MediaInformation struct {
+	ContentID   string `json:"contentId"`
+	ContentURL  string `json:"contentUrl,omitempty"`
+	StreamType  string `json:"streamType"`
+	ContentType string `json:"contentType"`
+	// Depends on its "metadataType", e.g. 0 for generic, 1 for movie or 3 for music
+	Metadata       map[string]interface{} `json:"metadata,omitempty"`
+	Duration       *float64               `json:"duration,omitempty"`
+	Tracks         []*MediaTrack          `json:"tracks,omitempty"`
+	TextTrackStyle map[string]interface{} `json:"textTrackStyle,omitempty"`
+	CustomData     json.RawMessage        `json:"customData,omitempty"`
+}
+
+// Media stream types
+const (
+	MediaStreamNone     = "NONE"
+	MediaStreamBuffered = "BUFFERED"
+	MediaStreamLive     = "LIVE"
+)
+
+type MediaTrack struct {
+	TrackID          int             `json:"trackId"`
+	Type             string          `json:"type"`
+	TrackContentID   string          `json:"trackContentId,omitempty"`
+	TrackContentType string          `json:"trackContentType,omitempty"`
+	Name             string          `json:"name,omitempty"`
+	Language         string          `json:"language,omitempty"`
+	Subtype          string          `json:"subtype,omitempty"`
+	CustomData       json.RawMessage `json:"customData,omitempty"`
+}
+
+type MediaQueueItem struct {
+	ItemID         *int              `json:"itemId,omitempty"`
+	Media          *MediaInformation `json:"media,omitempty"`
+	Autoplay       *bool             `json:"autoplay,omitempty"`
+	StartTime      *float64          `json:"startTime,omitempty"`
+	PreloadTime    *float64          `json:"preloadTime,omitempty"`
+	ActiveTrackIDs []int             `json:"activeTrackIds,omitempty"`
+	CustomData     json.RawMessage   `json:"customData,omitempty"`
+}
+
+// MediaStatus is the state of a media session
+type MediaStatus struct {
+	MediaSessionID         int               `json:"mediaSessionId"`
+	Media                  *MediaInformation `json:"media,omitempty"`
+	PlaybackRate           float64           `json:"playbackRate"`
+	PlayerState            string            `json:"playerState"`
+	IdleReason             string            `json:"idleReason,omitempty"`
+	CurrentTime            float64           `json:"currentTime"`
+	SupportedMediaCommands int               `json:"supportedMediaCommands"`
+	Volume                 *Volume           `json:"volume,omitempty"`
+	ActiveTrackIDs         []int             `json:"activeTrackIds,omitempty"`
+	RepeatMode             string            `json:"repeatMode,omitempty"`
+	CurrentItemID          *int              `json:"currentItemId,omitempty"`
+	Items                  []*MediaQueueItem `json:"items,omitempty"`
+	CustomData             json.RawMessage   `json:"customData,omitempty"`
+}
+
+// Media player states
+const (
+	MediaPlayerIdle      = "IDLE"
+	MediaPlayerPlaying   = "PLAYING"
+	MediaPlayerPaused    = "PAUSED"
+	MediaPlayerBuffering = "BUFFERING"
+)
+
+// Media idle reasons
+const (
+	MediaIdleCancelled   = "CANCELLED"
+	MediaIdleInterrupted = "INTERRUPTED"
+	MediaIdleFinished    = "FINISHED"
+	MediaIdleError       = "ERROR"
+)
+
+// MediaSessionPayload is the base of media requests on an existing media session
+type MediaSessionPayload struct {
+	Payload
+	MediaSessionID int             `json:"mediaSessionId"`
+	CustomData     json.RawMessage `json:"customData,omitempty"`
+}
+
+type MediaLoadPayload struct {
+	Payload
+	// Only sent by some senders, the app session ID
+	SessionID      string            `json:"sessionId,omitempty"`
+	Media          *MediaInformation `json:"media"`
+	Autoplay       *bool             `json:"autoplay,omitempty"`
+	CurrentTime    *float64          `json:"currentTime,omitempty"`
+	ActiveTrackIDs []int             `json:"activeTrackIds,omitempty"`
+	CustomData     json.RawMessage   `json:"customData,omitempty"`
+}
+
+type MediaPlayPayload struct {
+	MediaSessionPayload
+}
+
+type MediaPausePayload struct {
+	MediaSessionPayload
+}
+
+type MediaStopPayload struct {
+	MediaSessionPayload
+}
+
+type MediaSeekPayload struct {
+	MediaSessionPayload
+	// If empty, the player state is unchanged. Otherwise "PLAYBACK_START" or "PLAYBACK_PAUSE".
+	ResumeState string   `json:"resumeState,omitempty"`
+	CurrentTime *float64 `json:"currentTime,omitempty"`
+}
+
+type MediaGetStatusPayload struct {
+	Payload
+	// If nil, the status of every media session is requested
+	MediaSessionID *int `json:"mediaSessionId,omitempty"`
+}
+
+type MediaSetVolumePayload struct {
+	MediaSessionPayload
+	Volume *VolumeRequest `json:"volume"`
+}
+
+type MediaEditTracksInfoPayload struct {
+	MediaSessionPayload
+	ActiveTrackIDs []int                  `json:"activeTrackIds,omitempty"`
+	TextTrackStyle map[string]interface{} `json:"textTrackStyle,omitempty"`
+}
+
+type MediaQueueLoadPayload struct {
+	Payload
+	Items      []*MediaQueueItem `json:"items"`
+	StartIndex int               `json:"startIndex,omitempty"`
+	RepeatMode string            `json:"repeatMode,omitempty"`
+	CustomData json.RawMessage   `json:"customData,omitempty"`
+}
+
+type MediaQueueInsertPayload struct {
+	MediaSessionPayload
+	Items        []*MediaQueueItem `json:"items"`
+	InsertBefore *int              `json:"insertBefore,omitempty"`
+}
+
+type MediaQueueUpdatePayload struct {
+	MediaSessionPayload
+	Items         []*MediaQueueItem `json:"items,omitempty"`
+	CurrentItemID *int              `json:"currentItemId,omitempty"`
+	Jump          *int              `json:"jump,omitempty"`
+	RepeatMode    string            `json:"repeatMode,omitempty"`
+}
+
+type MediaQueueRemovePayload struct {
+	MediaSessionPayload
+	ItemIDs []int `json:"itemIds"`
+}
+
+type MediaQueueReorderPayload struct {
+	MediaSessionPayload
+	ItemIDs      []int `json:"itemIds"`
+	InsertBefore *int  `json:"insertBefore,omitempty"`
+}
+
+// MediaStatusPayload is the MEDIA_STATUS reply to media requests and what is broadcast on changes
+type MediaStatusPayload struct {
+	Payload
+	Status []*MediaStatus `json:"status"`
+}
+
+// MediaErrorPayload is the LOAD_FAILED, LOAD_CANCELLED, INVALID_PLAYER_STATE and INVALID_REQUEST reply to media
+// requests
+type MediaErrorPayload struct {
+	Payload
+	// Only set for INVALID_REQUEST
+	Reason     string          `json:"reason,omitempty"`
+	CustomData json.RawMessage `json:"customData,omitempty"`
+}
@@ -0,0 +1,182 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/cretz/owncast/owncast/server/cast_channel"
+)
+
+// TypedPayload is a JSON payload struct. Every payload struct implements it by embedding Payload.
+type TypedPayload interface {
+	BasePayload() *Payload
+}
+
+func (p *Payload) BasePayload() *Payload { return p }
+
+// PayloadKey identifies a payload struct by the namespace and the payload's "type"
+type PayloadKey struct {
+	Namespace string
+	Type      string
+}
+
+// PayloadError is a JSON payload that could not be decoded. Type and RequestID are set if they could be read.
+type PayloadError struct {
+	Namespace string
+	Type      string
+	RequestID *int
+	Err       error
+}
+
+func (p *PayloadError) Error() string {
+	ret := "Invalid payload on " + p.Namespace
+	if p.Type != "" {
+		ret += " of type " + p.Type
+	}
+	if p.RequestID != nil {
+		ret += fmt.Sprintf(" for request %v", *p.RequestID)
+	}
+	return ret + ": " + p.Err.Error()
+}
+
+func (p *PayloadError) Unwrap() error { return p.Err }
+
+// PayloadRegistry maps payload keys to the structs their JSON is decoded into. It is safe for concurrent use.
+type PayloadRegistry struct {
+	types     map[PayloadKey]func() TypedPayload
+	typesLock sync.RWMutex
+}
+
+func NewPayloadRegistry() *PayloadRegistry {
+	return &PayloadRegistry{types: map[PayloadKey]func() TypedPayload{}}
+}
+
+// DefaultPayloadRegistry contains the request and response payloads of the connection, heartbeat, receiver, discovery
+// and media namespaces
+func DefaultPayloadRegistry() *PayloadRegistry {
+	ret := NewPayloadRegistry()
+	// Connection
+	ret.Register(NamespaceConnection, "CONNECT", func() TypedPayload { return &ConnectPayload{} })
+	ret.Register(NamespaceConnection, "CLOSE", func() TypedPayload { return &ClosePayload{} })
+	// Heartbeat
+	ret.Register(NamespaceHeartbeat, "PING", func() TypedPayload { return &PingPayload{} })
+	ret.Register(NamespaceHeartbeat, "PONG", func() TypedPayload { return &PongPayload{} })
+	// Receiver, the GET_APP_AVAILABILITY response uses the request's type so only the request is here
+	ret.Register(NamespaceReceiver, "GET_APP_AVAILABILITY",
+		func() TypedPayload { return &GetAppAvailabilityRequestPayload{} })
+	ret.Register(NamespaceReceiver, "GET_STATUS", func() TypedPayload { return &GetReceiverStatusRequestPayload{} })
+	ret.Register(NamespaceReceiver, "RECEIVER_STATUS", func() TypedPayload { return &GetReceiverStatusResponsePayload{} })
+	ret.Register(NamespaceReceiver, "LAUNCH", func() TypedPayload { return &LaunchPayload{} })
+	ret.Register(NamespaceReceiver, "STOP", func() TypedPayload { return &StopPayload{} })
+	ret.Register(NamespaceReceiver, "SET_VOLUME", func() TypedPayload { return &SetVolumePayload{} })
+	ret.Register(NamespaceReceiver, ReceiverErrorLaunch, func() TypedPayload { return &ReceiverErrorPayload{} })
+	ret.Register(NamespaceReceiver, ReceiverErrorInvalidRequest, func() TypedPayload { return &ReceiverErrorPayload{} })
+	// Discovery
+	ret.Register(NamespaceDiscovery, "GET_DEVICE_INFO", func() TypedPayload { return &GetDeviceInfoPayload{} })
+	ret.Register(NamespaceDiscovery, "DEVICE_INFO", func() TypedPayload { return &DeviceInfoPayload{} })
+	// Media
+	ret.Register(NamespaceMedia, "LOAD", func() TypedPayload { return &MediaLoadPayload{} })
+	ret.Register(NamespaceMedia, "PLAY", func() TypedPayload { return &MediaPlayPayload{} })
+	ret.Register(NamespaceMedia, "PAUSE", func() TypedPayload { return &MediaPausePayload{} })
+	ret.Register(NamespaceMedia, "STOP", func() TypedPayload { return &MediaStopPayload{} })
+	ret.Register(NamespaceMedia, "SEEK", func() TypedPayload { return &MediaSeekPayload{} })
+	ret.Register(NamespaceMedia, "GET_STATUS", func() TypedPayload { return &MediaGetStatusPayload{} })
+	ret.Register(NamespaceMedia, "SET_VOLUME", func() TypedPayload { return &MediaSetVolumePayload{} })
+	ret.Register(NamespaceMedia, "EDIT_TRACKS_INFO", func() TypedPayload { return &MediaEditTracksInfoPayload{} })
+	ret.Register(NamespaceMedia, "QUEUE_LOAD", func() TypedPayload { return &MediaQueueLoadPayload{} })
+	ret.Register(NamespaceMedia, "QUEUE_INSERT", func() TypedPayload { return &MediaQueueInsertPayload{} })
+	ret.Register(NamespaceMedia, "QUEUE_UPDATE", func() TypedPayload { return &MediaQueueUpdatePayload{} })
+	ret.Register(NamespaceMedia, "QUEUE_REMOVE", func() TypedPayload { return &MediaQueueRemovePayload{} })
+	ret.Register(NamespaceMedia, "QUEUE_REORDER", func() TypedPayload { return &MediaQueueReorderPayload{} })
+	ret.Register(NamespaceMedia, "MEDIA_STATUS", func() TypedPayload { return &MediaStatusPayload{} })
+	ret.Register(NamespaceMedia, "LOAD_FAILED", func() TypedPayload { return &MediaErrorPayload{} })
+	ret.Register(NamespaceMedia, "LOAD_CANCELLED", func() TypedPayload { return &MediaErrorPayload{} })
+	ret.Register(NamespaceMedia, "INVALID_PLAYER_STATE", func() TypedPayload { return &MediaErrorPayload{} })
+	ret.Register(NamespaceMedia, "INVALID_REQUEST", func() TypedPayload { return &MediaErrorPayload{} })
+	return ret
+}
+
+// Register sets the function returning a new struct to decode the namespace's payloads of the type into, replacing
+// any existing one. A nil function removes it.
+func (p *PayloadRegistry) Register(namespace, typ string, newPayload func() TypedPayload) {
+	p.typesLock.Lock()
+	defer p.typesLock.Unlock()
+	if newPayload == nil {
+		delete(p.types, PayloadKey{namespace, typ})
+	} else {
+		p.types[PayloadKey{namespace, typ}] = newPayload
+	}
+}
+
+// New returns a new struct for the namespace's payloads of the type with the type set, or nil if none is registered
+func (p *PayloadRegistry) New(namespace, typ string) TypedPayload {
+	p.typesLock.RLock()
+	newPayload := p.types[PayloadKey{namespace, typ}]
+	p.typesLock.RUnlock()
+	if newPayload == nil {
+		return nil
+	}
+	ret := newPayload()
+	ret.BasePayload().Type = typ
+	return ret
+}
+
+// Decode decodes the message's JSON payload into the struct registered for its namespace and type, or a plain Payload
+// if there is none. The payload is first scanned for "type", which stops at that field, and then fully decoded, so
+// leading fields are read twice. Failures are a PayloadError.
+func (p *PayloadRegistry) Decode(castMessage *cast_channel.CastMessage) (TypedPayload, error) {
+	namespace := castMessage.GetNamespace()
+	if castMessage.PayloadUtf8 == nil {
+		return nil, &PayloadError{Namespace: namespace, Err: fmt.Errorf("Missing string payload")}
+	}
+	data := []byte(*castMessage.PayloadUtf8)
+	typ, err := scanPayloadType(data)
+	if err != nil {
+		return nil, &PayloadError{Namespace: namespace, Err: fmt.Errorf("Failed parsing JSON: %v", err)}
+	}
+	ret := p.New(namespace, typ)
+	if ret == nil {
+		ret = &Payload{}
+	}
+	if err = json.Unmarshal(data, ret); err != nil {
+		// Fields other than the bad one are still decoded, so the request ID is usually known
+		return nil, &PayloadError{
+			Namespace: namespace,
+			Type:      typ,
+			RequestID: ret.BasePayload().RequestID,
+			Err:       fmt.Errorf("Failed parsing JSON: %v", err),
+		}
+	}
+	return ret, nil
+}
+
+// scanPayloadType returns the "type" of the JSON object, or empty if it has none, reading no further than that field
+func scanPayloadType(data []byte) (string, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if tok, err := dec.Token(); err != nil {
+		return "", err
+	} else if tok != json.Delim('{') {
+		return "", fmt.Errorf("Payload is not a JSON object")
+	}
+	for dec.More() {
+		key, err := dec.Token()
+		if err != nil {
+			return "", err
+		}
+		if key == "type" {
+			var typ string
+			err = dec.Decode(&typ)
+			return typ, err
+		}
+		var skip json.RawMessage
+		if err = dec.Decode(&skip); err != nil {
+			return "", err
+		}
+	}
+	return "", nil
+}
+
+// Payloads returns the server's payload registry which can be changed while running
+func (s *Server) Payloads() *PayloadRegistry { return s.payloads }
@@ -0,0 +1,87 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/cretz/owncast/owncast/server/cast_channel"
+	"github.com/golang/protobuf/proto"
+)
+
+func TestScanPayloadType(t *testing.T) {
+	tests := []struct {
+		name        string
+		payload     string
+		expected    string
+		expectedErr bool
+	}{
+		{name: "type first", payload: `{"type":"LAUNCH","requestId":1}`, expected: "LAUNCH"},
+		{name: "type later", payload: `{"requestId":1,"nested":{"type":"X"},"list":[1,"a"],"type":"STOP"}`,
+			expected: "STOP"},
+		{name: "missing type", payload: `{"requestId":1}`, expected: ""},
+		{name: "empty object", payload: `{}`, expected: ""},
+		{name: "ignores after type", payload: `{"type":"PING",`, expected: "PING"},
+		{name: "not an object", payload: `["type","LAUNCH"]`, expectedErr: true},
+		{name: "string", payload: `"LAUNCH"`, expectedErr: true},
+		{name: "empty", payload: ``, expectedErr: true},
+		{name: "malformed", payload: `{"requestId":}`, expectedErr: true},
+		{name: "non-string type", payload: `{"type":5}`, expectedErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			typ, err := scanPayloadType([]byte(test.payload))
+			if test.expectedErr {
+				if err == nil {
+					t.Fatalf("Expected error, got %q", typ)
+				}
+			} else if err != nil {
+				t.Fatal(err)
+			} else if typ != test.expected {
+				t.Fatalf("Expected %q, got %q", test.expected, typ)
+			}
+		})
+	}
+}
+
+func newPayloadTestMessage(namespace, payload string) *cast_channel.CastMessage {
+	return &cast_channel.CastMessage{Namespace: proto.String(namespace), PayloadUtf8: proto.String(payload)}
+}
+
+func TestDecodePayload(t *testing.T) {
+	payloads := DefaultPayloadRegistry()
+	msg := newPayloadTestMessage(NamespaceReceiver, `{"requestId":3,"type":"LAUNCH","appId":"A"}`)
+	payload, err := payloads.Decode(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	launch, ok := payload.(*LaunchPayload)
+	if !ok || launch.Type != "LAUNCH" || launch.RequestID == nil || *launch.RequestID != 3 || launch.AppID != "A" {
+		t.Fatalf("Unexpected payload %#v", payload)
+	}
+	// Unregistered types are a plain payload
+	payload, err = payloads.Decode(newPayloadTestMessage("urn:x-cast:test", `{"type":"X"}`))
+	if _, ok := payload.(*Payload); !ok || err != nil || payload.BasePayload().Type != "X" {
+		t.Fatalf("Unexpected payload %#v, err %v", payload, err)
+	}
+	// Registries are separate
+	payloads.Register("urn:x-cast:test", "X", func() TypedPayload { return &LaunchPayload{} })
+	if payload, _ = payloads.Decode(newPayloadTestMessage("urn:x-cast:test", `{"type":"X"}`)); payload == nil {
+		t.Fatal("Expected payload")
+	} else if _, ok := payload.(*LaunchPayload); !ok {
+		t.Fatalf("Expected registered payload, got %#v", payload)
+	} else if DefaultPayloadRegistry().New("urn:x-cast:test", "X") != nil {
+		t.Fatal("Expected default registry to be unchanged")
+	}
+}
+
+func TestDecodePayloadError(t *testing.T) {
+	payloads := DefaultPayloadRegistry()
+	_, err := payloads.Decode(newPayloadTestMessage(NamespaceReceiver, `{"type":"LAUNCH","requestId":4,"appId":5}`))
+	payloadErr, ok := err.(*PayloadError)
+	if !ok || payloadErr.Type != "LAUNCH" || payloadErr.RequestID == nil || *payloadErr.RequestID != 4 {
+		t.Fatalf("Unexpected error %#v", err)
+	}
+	_, err = payloads.Decode(newPayloadTestMessage(NamespaceReceiver, `[]`))
+	if payloadErr, ok = err.(*PayloadError); !ok || payloadErr.Type != "" || payloadErr.RequestID != nil {
+		t.Fatalf("Unexpected error %#v", err)
+	}
+}
@@ -0,0 +1,205 @@
+package server
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+
+	"github.com/cretz/owncast/owncast/log"
+)
+
+// ProcessAppStopTimeout is how long a stopped process has to exit before it is killed
+var ProcessAppStopTimeout = 5 * time.Second
+
+// ProcessAppWriteTimeout is how long writing a line to a process's stdin can take before the process is killed
+var ProcessAppWriteTimeout = 5 * time.Second
+
+// ProcessAppQueueSize is how many lines can wait to be written to a process's stdin. The process is killed if more
+// are sent.
+var ProcessAppQueueSize = 64
+
+// ProcessApp is an App that runs a command and exchanges AppLine JSON, one per line, over its stdin and stdout. Only
+// messages on the session's namespaces are forwarded. The session is stopped when the process exits and the process is
+// stopped when the session is. Lines are written by their own goroutine, so a process that stops reading never blocks
+// senders.
+type ProcessApp struct {
+	Command []string
+
+	cmd      *exec.Cmd
+	stdin    *os.File
+	lines    chan []byte
+	stopped  chan struct{}
+	stopOnce sync.Once
+	exited   chan struct{}
+}
+
+func NewProcessApp(command ...string) *ProcessApp {
+	return &ProcessApp{
+		Command: command,
+		lines:   make(chan []byte, ProcessAppQueueSize),
+		stopped: make(chan struct{}),
+		exited:  make(chan struct{}),
+	}
+}
+
+func (p *ProcessApp) Launched(session *AppSession) error {
+	if len(p.Command) == 0 {
+		return fmt.Errorf("Missing command")
+	}
+	p.cmd = exec.Command(p.Command[0], p.Command[1:]...)
+	p.cmd.Stderr = os.Stderr
+	// Our own pipe instead of StdinPipe so writes can have a deadline
+	stdinReader, stdin, err := os.Pipe()
+	if err != nil {
+		return err
+	}
+	p.cmd.Stdin = stdinReader
+	stdout, err := p.cmd.StdoutPipe()
+	if err != nil {
+		stdinReader.Close()
+		stdin.Close()
+		return err
+	}
+	err = p.cmd.Start()
+	stdinReader.Close()
+	if err != nil {
+		stdin.Close()
+		return fmt.Errorf("Failed starting %v: %v", p.Command[0], err)
+	}
+	p.stdin = stdin
+	log.Debugf("Started process %v for session %v", p.cmd.Process.Pid, session.SessionID)
+	go p.runWriter()
+	go p.readStdout(session, stdout)
+	if err = p.write(session.launchedLine()); err != nil {
+		p.Stopped(session)
+		return fmt.Errorf("Failed writing to process: %v", err)
+	}
+	return nil
+}
+
+func (p *ProcessApp) SenderJoined(session *AppSession, sender *AppSender) {
+	if err := p.write(&AppLine{Type: "senderJoined", SenderID: sender.SenderID}); err != nil {
+		log.Debugf("Failed writing to process: %v", err)
+	}
+}
+
+func (p *ProcessApp) SenderLeft(session *AppSession, sender *AppSender) {
+	if err := p.write(&AppLine{Type: "senderLeft", SenderID: sender.SenderID}); err != nil {
+		log.Debugf("Failed writing to process: %v", err)
+	}
+}
+
+func (p *ProcessApp) HandleMessage(session *AppSession, sender *AppSender, msg Message) error {
+	castMsg := msg.CastMessage()
+	if !session.hasNamespace(castMsg.GetNamespace()) {
+		log.Debugf("Ignoring message for process on namespace %v", castMsg.GetNamespace())
+		return nil
+	}
+	if err := p.write(messageLine(sender, castMsg)); err != nil {
+		// The process going away stops the session, the sender isn't at fault
+		log.Debugf("Failed writing to process: %v", err)
+	}
+	return nil
+}
+
+// Stopped does not block. The writer sends "stopped" and then closes stdin, and the process is killed if it hasn't
+// exited after the stop timeout.
+func (p *ProcessApp) Stopped(session *AppSession) {
+	p.stopOnce.Do(func() {
+		close(p.stopped)
+		go func() {
+			select {
+			case <-p.exited:
+			case <-time.After(ProcessAppStopTimeout):
+				log.Debugf("Process %v did not exit, killing", p.cmd.Process.Pid)
+				p.cmd.Process.Kill()
+			}
+		}()
+	})
+}
+
+// write queues the line for the writer. If the queue is full, the process is killed.
+func (p *ProcessApp) write(line *AppLine) error {
+	byts, err := json.Marshal(line)
+	if err != nil {
+		return err
+	}
+	select {
+	case <-p.stopped:
+		return fmt.Errorf("Process stopped")
+	default:
+	}
+	select {
+	case p.lines <- append(byts, '\n'):
+		return nil
+	default:
+		log.Debugf("Process %v is not reading its input, killing", p.cmd.Process.Pid)
+		p.cmd.Process.Kill()
+		return fmt.Errorf("Process input queue full")
+	}
+}
+
+// runWriter writes queued lines until stopped or a write fails. A process that can't take a line within the write
+// timeout is killed.
+func (p *ProcessApp) runWriter() {
+	defer p.stdin.Close()
+	for {
+		select {
+		case byts := <-p.lines:
+			if !p.writeStdin(byts) {
+				return
+			}
+		case <-p.stopped:
+			// Flush what is already queued, only this goroutine receives
+			for len(p.lines) > 0 {
+				if !p.writeStdin(<-p.lines) {
+					return
+				}
+			}
+			if byts, err := json.Marshal(&AppLine{Type: "stopped"}); err == nil {
+				p.writeStdin(append(byts, '\n'))
+			}
+			return
+		}
+	}
+}
+
+func (p *ProcessApp) writeStdin(byts []byte) bool {
+	// Not every platform supports pipe deadlines, those just block until the process exits or is killed
+	p.stdin.SetWriteDeadline(time.Now().Add(ProcessAppWriteTimeout))
+	if _, err := p.stdin.Write(byts); err != nil {
+		log.Debugf("Failed writing to process %v, killing: %v", p.cmd.Process.Pid, err)
+		p.cmd.Process.Kill()
+		return false
+	}
+	return true
+}
+
+func (p *ProcessApp) readStdout(session *AppSession, stdout io.Reader) {
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(nil, 1024*1024)
+	for scanner.Scan() {
+		line := &AppLine{}
+		err := json.Unmarshal(scanner.Bytes(), line)
+		if err == nil {
+			err = session.sendAppLine(line)
+		}
+		if err != nil {
+			log.Debugf("Bad line from process %v: %v", p.cmd.Process.Pid, err)
+		}
+	}
+	// Stdout is no longer drained, e.g. after a too long line, so the process could block forever writing to it
+	if err := scanner.Err(); err != nil {
+		log.Infof("Failed reading from process %v, killing: %v", p.cmd.Process.Pid, err)
+		p.cmd.Process.Kill()
+	}
+	err := p.cmd.Wait()
+	log.Debugf("Process %v for session %v exited: %v", p.cmd.Process.Pid, session.SessionID, err)
+	close(p.exited)
+	session.server.StopApp(session.SessionID)
+}
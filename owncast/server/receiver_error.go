@@ -0,0 +1,45 @@
+package server
+
+// Receiver error reply types
+const (
+	ReceiverErrorLaunch         = "LAUNCH_ERROR"
+	ReceiverErrorInvalidRequest = "INVALID_REQUEST"
+)
+
+// Receiver error reasons
+const (
+	ReasonBadParameter     = "BAD_PARAMETER"
+	ReasonCancelled        = "CANCELLED"
+	ReasonNotAllowed       = "NOT_ALLOWED"
+	ReasonNotFound         = "NOT_FOUND"
+	ReasonInvalidCommand   = "INVALID_COMMAND"
+	ReasonInvalidSessionID = "INVALID_SESSION_ID"
+)
+
+// ReceiverError is an error reply to a receiver request, e.g. LAUNCH_ERROR with NOT_FOUND
+type ReceiverError struct {
+	// Usually ReceiverErrorLaunch or ReceiverErrorInvalidRequest
+	Type string
+	// If empty, no reason is sent
+	Reason string
+}
+
+func (r *ReceiverError) Error() string {
+	if r.Reason == "" {
+		return r.Type
+	}
+	return r.Type + ": " + r.Reason
+}
+
+// reply sends the error with the request ID of the message it answers
+func (r *ReceiverError) reply(conn *Conn, to Message) error {
+	return conn.ReplyPayload(to.CastMessage(), &ReceiverErrorPayload{
+		Payload: Payload{Type: r.Type, RequestID: messageRequestID(to)},
+		Reason:  r.Reason,
+	})
+}
+
+// ReceiverRequestPolicy is consulted before each receiver request is handled. If it returns an error, the sender is
+// sent that instead, e.g. LAUNCH_ERROR with NOT_ALLOWED to forbid launching an app. Requests that are invalid are
+// InvalidReceiverRequestMessage.
+type ReceiverRequestPolicy func(conn *Conn, msg Message) *ReceiverError
@@ -0,0 +1,99 @@
+package server
+
+import "github.com/cretz/owncast/owncast/log"
+
+func defaultReceiverStatus() *ReceiverStatus {
+	return &ReceiverStatus{
+		Applications:  []*ApplicationSession{},
+		IsActiveInput: true,
+		Volume:        &Volume{Level: 1},
+	}
+}
+
+// ReceiverStatus returns a copy of the current receiver status
+func (s *Server) ReceiverStatus() *ReceiverStatus {
+	s.receiverStatusLock.Lock()
+	defer s.receiverStatusLock.Unlock()
+	return s.receiverStatus.clone()
+}
+
+// UpdateReceiverStatus mutates the receiver status under lock. If the function returns true, the status is considered
+// changed and broadcast to every connected platform channel. Changes are broadcast in the order they are made, so
+// neither the function nor outbound interceptors may update the receiver status. A copy of the resulting status is
+// returned.
+func (s *Server) UpdateReceiverStatus(fn func(status *ReceiverStatus) bool) *ReceiverStatus {
+	return s.updateReceiverStatus(nil, "", fn)
+}
+
+// updateReceiverStatus is UpdateReceiverStatus except the broadcast is not sent to the given conn/remote ID since it
+// is the sender that requested the change and will get its own reply
+func (s *Server) updateReceiverStatus(
+	exceptConn *Conn,
+	exceptRemoteID string,
+	fn func(status *ReceiverStatus) bool,
+) *ReceiverStatus {
+	// Held until queued to every conn so senders never get an older status after a newer one. Separate from the status
+	// lock so the status can still be read while broadcasting.
+	s.receiverStatusOrderLock.Lock()
+	defer s.receiverStatusOrderLock.Unlock()
+	s.receiverStatusLock.Lock()
+	changed := fn(s.receiverStatus)
+	status := s.receiverStatus.clone()
+	s.receiverStatusLock.Unlock()
+	if changed {
+		s.broadcastReceiverStatus(status, exceptConn, exceptRemoteID)
+	}
+	return status
+}
+
+func (s *Server) broadcastReceiverStatus(status *ReceiverStatus, exceptConn *Conn, exceptRemoteID string) {
+	// Unsolicited status has a request ID of 0
+	payload := &GetReceiverStatusResponsePayload{
+		Payload: Payload{Type: "RECEIVER_STATUS", RequestID: new(int)},
+		Status:  status,
+	}
+	for _, conn := range s.Conns() {
+		for _, vc := range conn.VirtualConns() {
+			if vc.LocalID != PlatformReceiverID || (conn == exceptConn && vc.RemoteID == exceptRemoteID) {
+				continue
+			}
+			if err := conn.SendPayload(vc.LocalID, vc.RemoteID, NamespaceReceiver, payload); err != nil {
+				log.Debugf("Failed broadcasting receiver status to %v: %v", vc.RemoteID, err)
+			}
+		}
+	}
+}
+
+// SetStandBy sets whether the display is in standby and broadcasts the new receiver status if it changed
+func (s *Server) SetStandBy(standBy bool) *ReceiverStatus {
+	return s.UpdateReceiverStatus(func(status *ReceiverStatus) bool {
+		changed := status.IsStandBy != standBy
+		status.IsStandBy = standBy
+		return changed
+	})
+}
+
+// SetActiveInput sets whether the display's input is on the receiver and broadcasts the new receiver status if it
+// changed
+func (s *Server) SetActiveInput(activeInput bool) *ReceiverStatus {
+	return s.UpdateReceiverStatus(func(status *ReceiverStatus) bool {
+		changed := status.IsActiveInput != activeInput
+		status.IsActiveInput = activeInput
+		return changed
+	})
+}
+
+func (r *ReceiverStatus) clone() *ReceiverStatus {
+	ret := *r
+	ret.Applications = make([]*ApplicationSession, len(r.Applications))
+	for i, app := range r.Applications {
+		appCopy := *app
+		appCopy.Namespaces = append([]string(nil), app.Namespaces...)
+		ret.Applications[i] = &appCopy
+	}
+	if r.Volume != nil {
+		volumeCopy := *r.Volume
+		ret.Volume = &volumeCopy
+	}
+	return &ret
+}
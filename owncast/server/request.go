@@ -0,0 +1,156 @@
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"sync/atomic"
+)
+
+type requestKey struct {
+	localID   string
+	remoteID  string
+	namespace string
+	requestID int
+}
+
+type pendingRequest struct {
+	replyCh chan Message
+	match   func(Message) bool
+}
+
+// Request IDs start at a random point at least this high since senders usually number their own requests from 1
+const requestIDBase = 1 << 30
+
+// initialRequestID is the ID before the first one a conn's requests use
+func initialRequestID() int32 {
+	b := make([]byte, 4)
+	rand.Read(b)
+	return requestIDBase + int32(binary.BigEndian.Uint32(b)%(1<<29))
+}
+
+// Request sends the JSON payload from receiver-0 with a new request ID and waits for the reply with the same ID from
+// the destination on the namespace. The payload's requestId, if any, is replaced. Replies are matched regardless of
+// order and are not handled otherwise. If the context has no deadline, the server's request timeout is used.
+func (c *Conn) Request(ctx context.Context, namespace, destinationID string, payload interface{}) (Message, error) {
+	return c.request(ctx, PlatformReceiverID, destinationID, namespace, payload, nil)
+}
+
+// RequestMatching is Request where only messages the match function returns true for are taken as the reply, e.g. to
+// only accept certain response types. Other messages with the request ID are handled normally. The function must not
+// block.
+func (c *Conn) RequestMatching(
+	ctx context.Context,
+	namespace string,
+	destinationID string,
+	payload interface{},
+	match func(Message) bool,
+) (Message, error) {
+	return c.request(ctx, PlatformReceiverID, destinationID, namespace, payload, match)
+}
+
+// Request is Conn.Request from the session's transport ID to the sender
+func (a *AppSender) Request(ctx context.Context, namespace string, payload interface{}) (Message, error) {
+	return a.Conn.request(ctx, a.session.TransportID, a.SenderID, namespace, payload, nil)
+}
+
+// RequestMatching is Conn.RequestMatching from the session's transport ID to the sender
+func (a *AppSender) RequestMatching(
+	ctx context.Context,
+	namespace string,
+	payload interface{},
+	match func(Message) bool,
+) (Message, error) {
+	return a.Conn.request(ctx, a.session.TransportID, a.SenderID, namespace, payload, match)
+}
+
+func (c *Conn) request(
+	ctx context.Context,
+	sourceID string,
+	destinationID string,
+	namespace string,
+	payload interface{},
+	match func(Message) bool,
+) (Message, error) {
+	if destinationID == BroadcastID {
+		return nil, fmt.Errorf("Cannot request from broadcast destination")
+	}
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.server.requestTimeout)
+		defer cancel()
+	}
+	// Set the request ID on whatever JSON object we are given
+	byts, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("Failed marshalling payload: %v", err)
+	}
+	fields := map[string]json.RawMessage{}
+	if err = json.Unmarshal(byts, &fields); err != nil {
+		return nil, fmt.Errorf("Payload is not a JSON object: %v", err)
+	}
+	key := requestKey{sourceID, destinationID, namespace, int(atomic.AddInt32(&c.lastRequestID, 1))}
+	fields["requestId"] = json.RawMessage(fmt.Sprint(key.requestID))
+	if byts, err = json.Marshal(fields); err != nil {
+		return nil, fmt.Errorf("Failed marshalling payload: %v", err)
+	}
+	replyCh := make(chan Message, 1)
+	c.requestsLock.Lock()
+	c.requests[key] = &pendingRequest{replyCh, match}
+	c.requestsLock.Unlock()
+	defer func() {
+		c.requestsLock.Lock()
+		delete(c.requests, key)
+		c.requestsLock.Unlock()
+	}()
+	if err = c.SendStringMessage(sourceID, destinationID, namespace, string(byts)); err != nil {
+		return nil, err
+	}
+	select {
+	case reply := <-replyCh:
+		return reply, nil
+	case <-c.closed:
+		return nil, ErrConnClosed
+	case <-ctx.Done():
+		return nil, fmt.Errorf("Failed waiting for reply to request %v: %w", key.requestID, ctx.Err())
+	}
+}
+
+// resolveRequest passes the message to the pending request it replies to, returning false if there is none
+func (c *Conn) resolveRequest(msg Message) bool {
+	c.requestsLock.Lock()
+	defer c.requestsLock.Unlock()
+	if len(c.requests) == 0 {
+		return false
+	}
+	castMsg := msg.CastMessage()
+	requestID := messageRequestID(msg)
+	if requestID == nil {
+		return false
+	}
+	key := requestKey{castMsg.GetDestinationId(), castMsg.GetSourceId(), castMsg.GetNamespace(), *requestID}
+	pending := c.requests[key]
+	if pending == nil || (pending.match != nil && !pending.match(msg)) {
+		return false
+	}
+	delete(c.requests, key)
+	pending.replyCh <- msg
+	return true
+}
+
+// messageRequestID returns the requestId of the message's payload or nil if there is none. Only messages not decoded
+// into a TypedPayload, such as those on app namespaces, have their JSON parsed again for it.
+func messageRequestID(msg Message) *int {
+	if payload, ok := msg.(TypedPayload); ok {
+		return payload.BasePayload().RequestID
+	}
+	var payload struct {
+		RequestID *int `json:"requestId"`
+	}
+	if json.Unmarshal([]byte(msg.CastMessage().GetPayloadUtf8()), &payload) != nil {
+		return nil
+	}
+	return payload.RequestID
+}
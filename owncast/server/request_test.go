@@ -0,0 +1,276 @@
+package server
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/cretz/owncast/owncast/server/cast_channel"
+	"github.com/golang/protobuf/proto"
+)
+
+const testNamespace = "urn:x-cast:test"
+
+// newPipeTestServer returns a server with only what served conns and app sessions need
+func newPipeTestServer(apps ...*AppInfo) *Server {
+	payloads := DefaultPayloadRegistry()
+	return &Server{
+		handlers:              DefaultHandlers(payloads),
+		payloads:              payloads,
+		sendQueueSize:         64,
+		writeTimeout:          time.Second,
+		maxMessageSize:        64 * 1024,
+		maxChunkedMessageSize: 1024 * 1024,
+		readTimeout:           time.Second,
+		idleTimeout:           time.Minute,
+		requestTimeout:        time.Second,
+		rejoinGracePeriod:     time.Minute,
+		receiverStatus:        defaultReceiverStatus(),
+		sessions:              map[string]*AppSession{},
+		apps:                  NewAppRegistry(apps...),
+		conns:                 map[*Conn]struct{}{},
+	}
+}
+
+// pipeTestPeer is the sender end of a conn from newPipeTestConn
+type pipeTestPeer struct {
+	t        *testing.T
+	conn     net.Conn
+	received chan *cast_channel.CastMessage
+	served   chan struct{}
+}
+
+// newPipeTestConn returns a conn over a pipe that is served with ServeConn and the peer to send and receive on
+func newPipeTestConn(t *testing.T, s *Server) (*Conn, *pipeTestPeer) {
+	local, remote := net.Pipe()
+	conn := s.newConn(local)
+	peer := &pipeTestPeer{
+		t:        t,
+		conn:     remote,
+		received: make(chan *cast_channel.CastMessage, 64),
+		served:   make(chan struct{}),
+	}
+	go peer.receiveFrames()
+	go func() {
+		defer close(peer.served)
+		s.ServeConn(conn)
+	}()
+	return conn, peer
+}
+
+func (p *pipeTestPeer) receiveFrames() {
+	for {
+		size := make([]byte, 4)
+		if _, err := io.ReadFull(p.conn, size); err != nil {
+			close(p.received)
+			return
+		}
+		byts := make([]byte, binary.BigEndian.Uint32(size))
+		if _, err := io.ReadFull(p.conn, byts); err != nil {
+			close(p.received)
+			return
+		}
+		msg := &cast_channel.CastMessage{}
+		if err := proto.Unmarshal(byts, msg); err != nil {
+			p.t.Errorf("Invalid frame: %v", err)
+		}
+		p.received <- msg
+	}
+}
+
+// close closes the peer's end, like a lost conn, and waits for the conn to stop being served
+func (p *pipeTestPeer) close() {
+	p.conn.Close()
+	<-p.served
+}
+
+func (p *pipeTestPeer) send(sourceID, destinationID, namespace, payload string) {
+	byts, err := proto.Marshal(&cast_channel.CastMessage{
+		ProtocolVersion: cast_channel.CastMessage_CASTV2_1_0.Enum(),
+		SourceId:        proto.String(sourceID),
+		DestinationId:   proto.String(destinationID),
+		Namespace:       proto.String(namespace),
+		PayloadType:     cast_channel.CastMessage_STRING.Enum(),
+		PayloadUtf8:     proto.String(payload),
+	})
+	if err != nil {
+		p.t.Fatal(err)
+	}
+	size := make([]byte, 4)
+	binary.BigEndian.PutUint32(size, uint32(len(byts)))
+	if _, err = p.conn.Write(append(size, byts...)); err != nil {
+		p.t.Fatal(err)
+	}
+}
+
+// receive returns the next message on the namespace, skipping others
+func (p *pipeTestPeer) receive(namespace string) *cast_channel.CastMessage {
+	timeout := time.After(time.Second)
+	for {
+		select {
+		case msg, ok := <-p.received:
+			if !ok {
+				p.t.Fatal("Conn closed")
+			} else if msg.GetNamespace() == namespace {
+				return msg
+			}
+		case <-timeout:
+			p.t.Fatalf("Timed out waiting for message on %v", namespace)
+		}
+	}
+}
+
+// receiveRequestID returns the request ID of the next message on the namespace
+func (p *pipeTestPeer) receiveRequestID(namespace string) int {
+	var payload Payload
+	if err := json.Unmarshal([]byte(p.receive(namespace).GetPayloadUtf8()), &payload); err != nil {
+		p.t.Fatal(err)
+	} else if payload.RequestID == nil {
+		p.t.Fatal("Missing request ID")
+	}
+	return *payload.RequestID
+}
+
+type requestTestResult struct {
+	msg Message
+	err error
+}
+
+func startTestRequest(
+	ctx context.Context,
+	conn *Conn,
+	match func(Message) bool,
+) <-chan requestTestResult {
+	ret := make(chan requestTestResult, 1)
+	go func() {
+		msg, err := conn.RequestMatching(ctx, testNamespace, "sender-0", map[string]string{"type": "GET"}, match)
+		ret <- requestTestResult{msg, err}
+	}()
+	return ret
+}
+
+func awaitTestRequest(t *testing.T, resultCh <-chan requestTestResult) requestTestResult {
+	select {
+	case result := <-resultCh:
+		return result
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timed out waiting for request")
+		return requestTestResult{}
+	}
+}
+
+func TestRequestOutOfOrderReplies(t *testing.T) {
+	conn, peer := newPipeTestConn(t, newPipeTestServer())
+	defer peer.close()
+	first := startTestRequest(context.Background(), conn, nil)
+	firstID := peer.receiveRequestID(testNamespace)
+	second := startTestRequest(context.Background(), conn, nil)
+	secondID := peer.receiveRequestID(testNamespace)
+	if firstID < requestIDBase || secondID != firstID+1 {
+		t.Fatalf("Unexpected request IDs %v and %v", firstID, secondID)
+	}
+	peer.send("sender-0", PlatformReceiverID, testNamespace, fmt.Sprintf(`{"type":"B","requestId":%v}`, secondID))
+	peer.send("sender-0", PlatformReceiverID, testNamespace, fmt.Sprintf(`{"type":"A","requestId":%v}`, firstID))
+	for _, expected := range []struct {
+		resultCh <-chan requestTestResult
+		payload  string
+	}{
+		{first, fmt.Sprintf(`{"type":"A","requestId":%v}`, firstID)},
+		{second, fmt.Sprintf(`{"type":"B","requestId":%v}`, secondID)},
+	} {
+		result := awaitTestRequest(t, expected.resultCh)
+		if result.err != nil {
+			t.Fatal(result.err)
+		} else if payload := result.msg.CastMessage().GetPayloadUtf8(); payload != expected.payload {
+			t.Fatalf("Expected %v, got %v", expected.payload, payload)
+		}
+	}
+}
+
+func TestRequestTimeout(t *testing.T) {
+	conn, peer := newPipeTestConn(t, newPipeTestServer())
+	defer peer.close()
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	resultCh := startTestRequest(ctx, conn, nil)
+	requestID := peer.receiveRequestID(testNamespace)
+	result := awaitTestRequest(t, resultCh)
+	if !errors.Is(result.err, context.DeadlineExceeded) {
+		t.Fatalf("Expected deadline exceeded, got %v", result.err)
+	}
+	conn.requestsLock.Lock()
+	pending := len(conn.requests)
+	conn.requestsLock.Unlock()
+	if pending != 0 {
+		t.Fatalf("Expected no pending requests, got %v", pending)
+	}
+	// A late reply is handled like any other message
+	peer.send("sender-0", PlatformReceiverID, testNamespace, fmt.Sprintf(`{"type":"A","requestId":%v}`, requestID))
+}
+
+func TestRequestServerTimeout(t *testing.T) {
+	s := newPipeTestServer()
+	s.requestTimeout = 50 * time.Millisecond
+	conn, peer := newPipeTestConn(t, s)
+	defer peer.close()
+	resultCh := startTestRequest(context.Background(), conn, nil)
+	peer.receive(testNamespace)
+	if result := awaitTestRequest(t, resultCh); !errors.Is(result.err, context.DeadlineExceeded) {
+		t.Fatalf("Expected deadline exceeded, got %v", result.err)
+	}
+}
+
+func TestRequestMatching(t *testing.T) {
+	conn, peer := newPipeTestConn(t, newPipeTestServer())
+	defer peer.close()
+	resultCh := startTestRequest(context.Background(), conn, func(msg Message) bool {
+		return messageType(msg) == "RESPONSE"
+	})
+	requestID := peer.receiveRequestID(testNamespace)
+	// Same ID, but not a response, as when the sender numbers its own requests the same
+	peer.send("sender-0", PlatformReceiverID, testNamespace, fmt.Sprintf(`{"type":"GET","requestId":%v}`, requestID))
+	select {
+	case result := <-resultCh:
+		t.Fatalf("Expected no reply yet, got %v", result)
+	case <-time.After(50 * time.Millisecond):
+	}
+	// Other sources don't reply either
+	peer.send("sender-1", PlatformReceiverID, testNamespace, fmt.Sprintf(`{"type":"RESPONSE","requestId":%v}`, requestID))
+	peer.send("sender-0", PlatformReceiverID, testNamespace, fmt.Sprintf(`{"type":"RESPONSE","requestId":%v}`, requestID))
+	result := awaitTestRequest(t, resultCh)
+	if result.err != nil {
+		t.Fatal(result.err)
+	} else if result.msg.CastMessage().GetSourceId() != "sender-0" || messageType(result.msg) != "RESPONSE" {
+		t.Fatalf("Unexpected reply %v", result.msg.CastMessage())
+	}
+}
+
+func TestRequestConnClosed(t *testing.T) {
+	conn, peer := newPipeTestConn(t, newPipeTestServer())
+	resultCh := startTestRequest(context.Background(), conn, nil)
+	peer.receive(testNamespace)
+	peer.close()
+	if result := awaitTestRequest(t, resultCh); result.err != ErrConnClosed {
+		t.Fatalf("Expected conn closed, got %v", result.err)
+	}
+}
+
+func TestRequestBroadcast(t *testing.T) {
+	conn, peer := newPipeTestConn(t, newPipeTestServer())
+	defer peer.close()
+	if _, err := conn.Request(context.Background(), testNamespace, BroadcastID, map[string]string{}); err == nil {
+		t.Fatal("Expected error")
+	}
+}
+
+func messageType(msg Message) string {
+	var payload Payload
+	json.Unmarshal([]byte(msg.CastMessage().GetPayloadUtf8()), &payload)
+	return payload.Type
+}
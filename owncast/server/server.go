@@ -1,9 +1,14 @@
 package server
 
 import (
+	"context"
 	"crypto/tls"
 	"fmt"
 	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/cretz/owncast/owncast/cert"
 	"github.com/cretz/owncast/owncast/log"
@@ -15,9 +20,46 @@ type Server struct {
 	peerCert                  *cert.KeyPair
 	authCert                  *cert.KeyPair
 	tlsListener               net.Listener
+	tlsListenerLock           sync.RWMutex
 	tlsListenerCloseOnClose   bool
 	mdnsServer                *zeroconf.Server
 	mdnsServerShutdownOnClose bool
+	broadcastText             map[string]string
+	httpListener              net.Listener
+	httpServer                *http.Server
+	handlers                  map[string]Handler
+	handlersLock              sync.RWMutex
+	payloads                  *PayloadRegistry
+	interceptors              []Interceptor
+	interceptorsLock          sync.RWMutex
+	sendQueueSize             int
+	writeTimeout              time.Duration
+	sendQueueFullPolicy       SendQueueFullPolicy
+	maxMessageSize            int
+	readTimeout               time.Duration
+	idleTimeout               time.Duration
+	maxChunkedMessageSize     int
+	heartbeatInterval         time.Duration
+	heartbeatMaxMissed        int
+	shutdownTimeout           time.Duration
+	requestTimeout            time.Duration
+	rejoinGracePeriod         time.Duration
+	appStopPolicy             AppStopPolicy
+	volumeFile                string
+	volumeHook                VolumeHook
+	volumeLock                sync.Mutex
+	deviceAuthPolicy          DeviceAuthPolicy
+	receiverRequestPolicy     ReceiverRequestPolicy
+	crl                       []byte
+	receiverStatus            *ReceiverStatus
+	receiverStatusLock        sync.Mutex
+	receiverStatusOrderLock   sync.Mutex
+	sessions                  map[string]*AppSession
+	sessionsLock              sync.RWMutex
+	apps                      *AppRegistry
+	conns                     map[*Conn]struct{}
+	connsLock                 sync.RWMutex
+	shuttingDown              bool
 }
 
 // Just a random v4 uuid I gen'd and then removed dashes
@@ -40,9 +82,14 @@ type Conf struct {
 	// If empty, it is ":0"
 	TLSListenAddr string
 
-	// If empty, it is created with other data. If present, it will not be closed on close.
+	// If empty, it is created with other data. If present, it will not be closed on close. Serve stops accepting from it
+	// on shutdown, but a pending accept is only interrupted if it has a SetDeadline method like *net.TCPListener.
+	// Otherwise the caller must close it to not have one more conn accepted and dropped.
 	TLSListenerOverride net.Listener
 
+	// If empty, web apps are not served. Otherwise an address like ":8008" to serve WebAppHandler on.
+	HTTPListenAddr string
+
 	// If empty, is "OwnCast"
 	BroadcastInstanceName string
 	// If empty, is "OwnCast"
@@ -57,15 +104,161 @@ type Conf struct {
 
 	// If empty, uses DefaultID
 	ID string
+
+	// If zero, is 64. The number of outbound messages that can be queued per connection.
+	SendQueueSize int
+	// If zero, is 10 seconds. Used as the deadline for each write and as the max wait for SendQueueFullWait.
+	WriteTimeout time.Duration
+	// If empty, is SendQueueFullWait
+	SendQueueFullPolicy SendQueueFullPolicy
+
+	// If zero, is 65536 which is the Cast limit. Larger inbound frames are a ProtocolError.
+	MaxMessageSize int
+	// If zero, is 1 MiB. The max payload size of a message reassembled from chunks and of all of a conn's partially
+	// received ones together. Larger is a ProtocolError.
+	MaxChunkedMessageSize int
+	// If zero, is 10 seconds. The max time to read a message after its size is read. If negative, there is no limit.
+	ReadTimeout time.Duration
+	// If zero, is 5 minutes. The max time to wait for the next message. If negative, there is no limit.
+	IdleTimeout time.Duration
+
+	// If zero, is 5 seconds. How often to PING connected senders. If negative, no PINGs are sent and connections are
+	// never closed for missed heartbeats.
+	HeartbeatInterval time.Duration
+	// If zero, is 3. The number of consecutive heartbeat intervals without receiving anything before the connection is
+	// closed.
+	HeartbeatMaxMissed int
+
+	// If zero, is 5 seconds. How long Serve waits for connections to finish after its context is done.
+	ShutdownTimeout time.Duration
+	// If zero, is 10 seconds. How long Conn.Request waits for a reply when its context has no deadline.
+	RequestTimeout time.Duration
+
+	// If nil, is DefaultAppRegistry()
+	Apps *AppRegistry
+	// If zero, is 30 seconds. How long a sender whose conn is lost without CLOSE stays in its app session so it can
+	// rejoin by connecting to the transport ID again with the same sender ID. If negative, lost senders leave at once.
+	SenderRejoinGracePeriod time.Duration
+	// If empty, is AppStopOnLastStrongSender
+	AppStopPolicy AppStopPolicy
+	// If nil, every receiver request is handled normally
+	ReceiverRequestPolicy ReceiverRequestPolicy
+
+	// If empty, is VolumeControlAttenuation
+	VolumeControlType VolumeControlType
+	// If zero, is 0.05. The level increment senders should use for volume buttons.
+	VolumeStepInterval float64
+	// If empty, the volume is not persisted. Otherwise it is loaded from and saved to this JSON file.
+	VolumeFile string
+	// If nil, volume changes only affect the receiver status
+	VolumeHook VolumeHook
+
+	// If nil, every supported signature and hash algorithm is allowed
+	DeviceAuthPolicy DeviceAuthPolicy
+	// If present, a serialized Cast CRL bundle (e.g. from cert.RevocationList.GenerateCRL) sent in auth responses
+	CRL []byte
+
+	// Applied over DefaultHandlers, so entries here override the built-in ones. A nil value removes the built-in one.
+	Handlers map[string]Handler
+	// If nil, is DefaultPayloadRegistry(). What the built-in handlers decode JSON payloads with.
+	Payloads *PayloadRegistry
+	// Run in order for every inbound and outbound message, the first being the outermost
+	Interceptors []Interceptor
 }
 
 func Listen(conf *Conf) (*Server, error) {
 	s := &Server{
-		intermediateCACerts: conf.IntermediateCACerts,
-		peerCert:            conf.PeerCert,
-		authCert:            conf.AuthCert,
-		tlsListener:         conf.TLSListenerOverride,
-		mdnsServer:          conf.BroadcastServerOverride,
+		intermediateCACerts:   conf.IntermediateCACerts,
+		peerCert:              conf.PeerCert,
+		authCert:              conf.AuthCert,
+		tlsListener:           conf.TLSListenerOverride,
+		mdnsServer:            conf.BroadcastServerOverride,
+		payloads:              conf.Payloads,
+		interceptors:          append([]Interceptor(nil), conf.Interceptors...),
+		sendQueueSize:         conf.SendQueueSize,
+		writeTimeout:          conf.WriteTimeout,
+		sendQueueFullPolicy:   conf.SendQueueFullPolicy,
+		maxMessageSize:        conf.MaxMessageSize,
+		readTimeout:           conf.ReadTimeout,
+		idleTimeout:           conf.IdleTimeout,
+		maxChunkedMessageSize: conf.MaxChunkedMessageSize,
+		heartbeatInterval:     conf.HeartbeatInterval,
+		heartbeatMaxMissed:    conf.HeartbeatMaxMissed,
+		shutdownTimeout:       conf.ShutdownTimeout,
+		requestTimeout:        conf.RequestTimeout,
+		rejoinGracePeriod:     conf.SenderRejoinGracePeriod,
+		appStopPolicy:         conf.AppStopPolicy,
+		volumeFile:            conf.VolumeFile,
+		volumeHook:            conf.VolumeHook,
+		deviceAuthPolicy:      conf.DeviceAuthPolicy,
+		receiverRequestPolicy: conf.ReceiverRequestPolicy,
+		crl:                   conf.CRL,
+		receiverStatus:        defaultReceiverStatus(),
+		sessions:              map[string]*AppSession{},
+		apps:                  conf.Apps,
+		conns:                 map[*Conn]struct{}{},
+	}
+	if s.sendQueueSize <= 0 {
+		s.sendQueueSize = 64
+	}
+	if s.writeTimeout <= 0 {
+		s.writeTimeout = 10 * time.Second
+	}
+	if s.maxMessageSize <= 0 {
+		s.maxMessageSize = 64 * 1024
+	}
+	if s.maxChunkedMessageSize <= 0 {
+		s.maxChunkedMessageSize = 1024 * 1024
+	}
+	if s.readTimeout == 0 {
+		s.readTimeout = 10 * time.Second
+	}
+	if s.idleTimeout == 0 {
+		s.idleTimeout = 5 * time.Minute
+	}
+	if s.heartbeatInterval == 0 {
+		s.heartbeatInterval = 5 * time.Second
+	}
+	if s.heartbeatMaxMissed <= 0 {
+		s.heartbeatMaxMissed = 3
+	}
+	if s.apps == nil {
+		s.apps = DefaultAppRegistry()
+	}
+	if s.payloads == nil {
+		s.payloads = DefaultPayloadRegistry()
+	}
+	s.handlers = DefaultHandlers(s.payloads)
+	if s.shutdownTimeout <= 0 {
+		s.shutdownTimeout = 5 * time.Second
+	}
+	if s.requestTimeout <= 0 {
+		s.requestTimeout = 10 * time.Second
+	}
+	if s.rejoinGracePeriod == 0 {
+		s.rejoinGracePeriod = 30 * time.Second
+	}
+	// Build mdns text, also used to answer device info requests even if the broadcast server is overridden
+	id := conf.ID
+	if id == "" {
+		id = DefaultID
+	}
+	s.broadcastText = DefaultBroadcastText(id)
+	if conf.BroadcastFriendlyName != "" {
+		s.broadcastText["fn"] = conf.BroadcastFriendlyName
+	}
+	for k, v := range conf.BroadcastTextOverrides {
+		if v == "" {
+			delete(s.broadcastText, k)
+		} else {
+			s.broadcastText[k] = v
+		}
+	}
+	if err := s.initVolume(conf); err != nil {
+		return nil, err
+	}
+	for namespace, handler := range conf.Handlers {
+		s.Handle(namespace, handler)
 	}
 	// Create the intermediate cert if necessary
 	if len(s.intermediateCACerts) == 0 {
@@ -122,27 +315,19 @@ func Listen(conf *Conf) (*Server, error) {
 			err = fmt.Errorf("TLS listener addr is not TCP")
 		}
 	}
+	// Serve web apps
+	if err == nil && conf.HTTPListenAddr != "" {
+		if s.httpListener, err = net.Listen("tcp", conf.HTTPListenAddr); err == nil {
+			log.Debugf("Serving web apps on %v", s.httpListener.Addr())
+			s.httpServer = &http.Server{Handler: s.WebAppHandler()}
+			go s.httpServer.Serve(s.httpListener)
+		}
+	}
 	// Start mdns
 	if err == nil && s.mdnsServer == nil {
 		s.mdnsServerShutdownOnClose = true
-		// Build mdns text
-		id := conf.ID
-		if id == "" {
-			id = DefaultID
-		}
-		broadcastTextMap := DefaultBroadcastText(id)
-		if conf.BroadcastFriendlyName != "" {
-			broadcastTextMap["fn"] = conf.BroadcastFriendlyName
-		}
-		for k, v := range conf.BroadcastTextOverrides {
-			if v == "" {
-				delete(broadcastTextMap, k)
-			} else {
-				broadcastTextMap[k] = v
-			}
-		}
 		broadcastText := []string{}
-		for k, v := range broadcastTextMap {
+		for k, v := range s.broadcastText {
 			broadcastText = append(broadcastText, k+"="+v)
 		}
 		// Start the server
@@ -177,16 +362,230 @@ func DefaultBroadcastText(id string) map[string]string {
 	}
 }
 
+// BroadcastText returns a copy of the mDNS TXT values built from DefaultBroadcastText and the conf. They are built even
+// if the broadcast server is overridden.
+func (s *Server) BroadcastText() map[string]string {
+	ret := make(map[string]string, len(s.broadcastText))
+	for k, v := range s.broadcastText {
+		ret[k] = v
+	}
+	return ret
+}
+
 func (s *Server) Close() (err error) {
 	if s.mdnsServerShutdownOnClose && s.mdnsServer != nil {
 		log.Debugf("Closing mDNS server")
 		s.mdnsServer.Shutdown()
 		s.mdnsServer = nil
 	}
+	if s.httpServer != nil {
+		log.Debugf("Closing HTTP server")
+		err = s.httpServer.Close()
+		s.httpServer = nil
+	}
+	s.tlsListenerLock.Lock()
+	defer s.tlsListenerLock.Unlock()
 	if s.tlsListenerCloseOnClose && s.tlsListener != nil {
 		log.Debugf("Closing TLS listener")
-		err = s.tlsListener.Close()
+		if closeErr := s.tlsListener.Close(); err == nil {
+			err = closeErr
+		}
 		s.tlsListener = nil
 	}
 	return
 }
+
+// ServeErrors are all errors collected by Serve
+type ServeErrors []error
+
+func (s ServeErrors) Error() string {
+	strs := make([]string, len(s))
+	for i, err := range s {
+		strs[i] = err.Error()
+	}
+	return strings.Join(strs, "; ")
+}
+
+// Serve is ServeFunc with ServeConn
+func (s *Server) Serve(ctx context.Context) error { return s.ServeFunc(ctx, s.ServeConn) }
+
+// ServeFunc accepts connections and runs serveConn for each in its own goroutine until the context is done or
+// accepting fails. Then it stops advertising and listening, stops every app session, sends CLOSE on every virtual
+// connection and waits up to the shutdown timeout for every serveConn to return. Errors from serveConn are only logged,
+// other errors from accepting or shutting down are returned as ServeErrors. The server is closed when this returns.
+func (s *Server) ServeFunc(ctx context.Context, serveConn func(*Conn) error) error {
+	var wg sync.WaitGroup
+	acceptErrCh := make(chan error, 1)
+	acceptDone := make(chan struct{})
+	go func() {
+		defer close(acceptDone)
+		for {
+			conn, err := s.Accept()
+			if err != nil {
+				acceptErrCh <- err
+				return
+			}
+			// An overridden listener is not closed on shutdown, so we may still accept after it starts
+			if !s.trackConn(conn, &wg) {
+				conn.abort()
+				return
+			}
+			go func() {
+				defer wg.Done()
+				defer s.untrackConn(conn)
+				if err := serveConn(conn); err != nil {
+					log.Debugf("Connection closed with error: %v", err)
+				}
+			}()
+		}
+	}()
+	var errs ServeErrors
+	select {
+	case <-ctx.Done():
+		log.Debugf("Shutting down server")
+	case err := <-acceptErrCh:
+		errs = append(errs, fmt.Errorf("Failed accepting: %v", err))
+	}
+	// Stop new conns, then wait on a snapshot of existing ones. The wait group is only added to by trackConn while not
+	// shutting down, so it is safe to wait on after this.
+	s.connsLock.Lock()
+	s.shuttingDown = true
+	s.connsLock.Unlock()
+	if err := s.Close(); err != nil {
+		errs = append(errs, fmt.Errorf("Failed closing server: %v", err))
+	}
+	shutdownDeadline := time.Now().Add(s.shutdownTimeout)
+	s.stopAccepting(acceptDone, shutdownDeadline)
+	var closeErrs []error
+	var closeErrsLock sync.Mutex
+	// Stopping apps and closing conns are part of the timed drain. Nothing can rejoin after this, so apps are stopped
+	// instead of left to their senders' grace periods, and first so their CLOSE and status reach the senders.
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		var stopWg sync.WaitGroup
+		for _, session := range s.Sessions() {
+			stopWg.Add(1)
+			go func(sessionID string) {
+				defer stopWg.Done()
+				s.StopApp(sessionID)
+			}(session.SessionID)
+		}
+		stopWg.Wait()
+		var closeWg sync.WaitGroup
+		for _, conn := range s.Conns() {
+			closeWg.Add(1)
+			go func(conn *Conn) {
+				defer closeWg.Done()
+				err := conn.CloseVirtualConns()
+				if closeErr := conn.Close(); err == nil {
+					err = closeErr
+				}
+				if err != nil {
+					closeErrsLock.Lock()
+					closeErrs = append(closeErrs, fmt.Errorf("Failed closing connection: %v", err))
+					closeErrsLock.Unlock()
+				}
+			}(conn)
+		}
+		closeWg.Wait()
+	}()
+	drained := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(drained)
+	}()
+	select {
+	case <-drained:
+	case <-time.After(time.Until(shutdownDeadline)):
+		remaining := s.Conns()
+		for _, conn := range remaining {
+			conn.abort()
+		}
+		errs = append(errs, fmt.Errorf("Timed out shutting down with %v connection(s) open", len(remaining)))
+	}
+	closeErrsLock.Lock()
+	errs = append(errs, closeErrs...)
+	closeErrsLock.Unlock()
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+// stopAccepting interrupts a pending accept on an overridden listener if it supports deadlines and waits for the accept
+// loop to return. The deadline is cleared afterwards so the caller can keep using the listener.
+func (s *Server) stopAccepting(acceptDone <-chan struct{}, deadline time.Time) {
+	s.tlsListenerLock.RLock()
+	listener, ok := s.tlsListener.(interface{ SetDeadline(time.Time) error })
+	s.tlsListenerLock.RUnlock()
+	if !ok {
+		return
+	}
+	if err := listener.SetDeadline(time.Now()); err != nil {
+		log.Debugf("Unable to interrupt accepting: %v", err)
+		return
+	}
+	select {
+	case <-acceptDone:
+	case <-time.After(time.Until(deadline)):
+	}
+	if err := listener.SetDeadline(time.Time{}); err != nil {
+		log.Debugf("Unable to clear listener deadline: %v", err)
+	}
+}
+
+// ServeConn receives and handles messages until the conn fails or is closed. Before the conn is closed, CLOSE is
+// sent on all of its virtual connections. Senders still connected then are treated as lost and may rejoin their app
+// session. The conn is tracked while served so it gets broadcasts such as RECEIVER_STATUS. Conns handled without
+// ServeConn or ServeFunc get no broadcasts.
+func (s *Server) ServeConn(conn *Conn) error {
+	if !s.trackConn(conn, nil) {
+		conn.abort()
+		return fmt.Errorf("Server is shutting down")
+	}
+	defer s.untrackConn(conn)
+	defer conn.Close()
+	defer conn.closeVirtualConns(true)
+	for {
+		msg, err := conn.ReceiveMessage()
+		if err != nil {
+			return fmt.Errorf("Unable to parse message: %w", err)
+		}
+		if err = s.HandleMessage(conn, msg); err != nil {
+			return fmt.Errorf("Failed handling message: %v", err)
+		}
+	}
+}
+
+// Conns returns a snapshot of the live connections being served
+func (s *Server) Conns() []*Conn {
+	s.connsLock.RLock()
+	defer s.connsLock.RUnlock()
+	ret := make([]*Conn, 0, len(s.conns))
+	for conn := range s.conns {
+		ret = append(ret, conn)
+	}
+	return ret
+}
+
+// trackConn returns false if shutting down. Otherwise the conn is tracked and the wait group, if not nil, is added to
+// under the same lock so a shutdown can't start waiting in between. Tracking an already tracked conn is harmless.
+func (s *Server) trackConn(conn *Conn, wg *sync.WaitGroup) bool {
+	s.connsLock.Lock()
+	defer s.connsLock.Unlock()
+	if s.shuttingDown {
+		return false
+	}
+	s.conns[conn] = struct{}{}
+	if wg != nil {
+		wg.Add(1)
+	}
+	return true
+}
+
+func (s *Server) untrackConn(conn *Conn) {
+	s.connsLock.Lock()
+	defer s.connsLock.Unlock()
+	delete(s.conns, conn)
+}
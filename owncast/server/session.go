@@ -0,0 +1,459 @@
+package server
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/cretz/owncast/owncast/log"
+)
+
+// AppSession is a launched app. Senders join it by connecting to its transport ID and messages they send to the
+// transport ID are routed to it.
+type AppSession struct {
+	AppID       string
+	SessionID   string
+	TransportID string
+	DisplayName string
+	Namespaces  []string
+	IconURL     string
+	StatusText  string
+
+	server *Server
+	app    App
+	// Both guarded by sendersLock
+	senders     map[senderKey]*AppSender
+	lostSenders map[string]*lostSender
+	sendersLock sync.RWMutex
+}
+
+// AppSender is a sender virtual connection joined to an app session
+type AppSender struct {
+	Conn     *Conn
+	SenderID string
+	ConnType VirtualConnType
+
+	session *AppSession
+}
+
+type senderKey struct {
+	conn     *Conn
+	senderID string
+}
+
+// lostSender is a sender whose conn went away without CLOSE and that may rejoin by sender ID until the timer fires
+type lostSender struct {
+	sender *AppSender
+	timer  *time.Timer
+}
+
+func newAppSession(s *Server, app *AppInfo) *AppSession {
+	ret := &AppSession{
+		AppID:       string(app.AppID),
+		SessionID:   newUUID(),
+		TransportID: newUUID(),
+		DisplayName: app.DisplayName,
+		Namespaces:  app.Namespaces,
+		IconURL:     app.IconURL,
+		StatusText:  "Ready To Cast",
+		server:      s,
+		senders:     map[senderKey]*AppSender{},
+		lostSenders: map[string]*lostSender{},
+	}
+	if app.NewApp != nil {
+		ret.app = app.NewApp()
+	} else if len(app.Command) > 0 {
+		ret.app = NewProcessApp(app.Command...)
+	} else if app.Dir != "" {
+		ret.app = NewWebApp()
+	}
+	return ret
+}
+
+func (a *AppSession) hasNamespace(namespace string) bool {
+	for _, ns := range a.Namespaces {
+		if ns == namespace {
+			return true
+		}
+	}
+	return false
+}
+
+// App returns the in-process app or nil if there is none
+func (a *AppSession) App() App { return a.app }
+
+// Senders returns a snapshot of the joined senders
+func (a *AppSession) Senders() []*AppSender {
+	a.sendersLock.RLock()
+	defer a.sendersLock.RUnlock()
+	ret := make([]*AppSender, 0, len(a.senders))
+	for _, sender := range a.senders {
+		ret = append(ret, sender)
+	}
+	return ret
+}
+
+// LostSenders returns a snapshot of the senders that lost their conn and may still rejoin
+func (a *AppSession) LostSenders() []*AppSender {
+	a.sendersLock.RLock()
+	defer a.sendersLock.RUnlock()
+	ret := make([]*AppSender, 0, len(a.lostSenders))
+	for _, lost := range a.lostSenders {
+		ret = append(ret, lost.sender)
+	}
+	return ret
+}
+
+func (a *AppSession) applicationSession() *ApplicationSession {
+	return &ApplicationSession{
+		AppID:           a.AppID,
+		DisplayName:     a.DisplayName,
+		Namespaces:      append([]string(nil), a.Namespaces...),
+		IconURL:         a.IconURL,
+		SessionID:       a.SessionID,
+		StatusText:      a.StatusText,
+		TransportID:     a.TransportID,
+		SenderConnected: a.senderConnected(),
+	}
+}
+
+// senderConnected is whether any joined sender is not invisible
+func (a *AppSession) senderConnected() bool {
+	a.sendersLock.RLock()
+	defer a.sendersLock.RUnlock()
+	return a.senderConnectedLocked()
+}
+
+func (a *AppSession) senderConnectedLocked() bool {
+	for _, sender := range a.senders {
+		if sender.ConnType != VirtualConnInvisible {
+			return true
+		}
+	}
+	return false
+}
+
+// remainingLocked is whether any joined or lost sender would keep the app running under the stop policy
+func (a *AppSession) remainingLocked(policy AppStopPolicy) bool {
+	for _, sender := range a.senders {
+		if policy.counts(sender) {
+			return true
+		}
+	}
+	for _, lost := range a.lostSenders {
+		if policy.counts(lost.sender) {
+			return true
+		}
+	}
+	return false
+}
+
+func (a *AppSession) sender(conn *Conn, senderID string) *AppSender {
+	a.sendersLock.RLock()
+	defer a.sendersLock.RUnlock()
+	return a.senders[senderKey{conn, senderID}]
+}
+
+func (a *AppSession) addSender(conn *Conn, senderID string, connType VirtualConnType) *AppSender {
+	a.sendersLock.Lock()
+	wasConnected := a.senderConnectedLocked()
+	key := senderKey{conn, senderID}
+	sender := a.senders[key]
+	joined := sender == nil
+	var lost *lostSender
+	if joined {
+		sender = &AppSender{Conn: conn, SenderID: senderID, ConnType: connType, session: a}
+		a.senders[key] = sender
+		// If the timer already fired, the sender has left and this is a fresh join
+		if lost = a.lostSenders[senderID]; lost != nil && lost.timer.Stop() {
+			delete(a.lostSenders, senderID)
+			log.Debugf("Sender %v rejoined session %v", senderID, a.SessionID)
+		} else {
+			lost = nil
+			log.Debugf("Sender %v joined session %v as %v", senderID, a.SessionID, connType)
+		}
+	}
+	statusChanged := wasConnected != a.senderConnectedLocked()
+	a.sendersLock.Unlock()
+	if statusChanged {
+		a.server.syncApplications(nil, "")
+	}
+	if a.app == nil || !joined {
+		return sender
+	}
+	if lost == nil {
+		a.app.SenderJoined(a, sender)
+	} else if app, ok := a.app.(AppWithSenderRejoin); ok {
+		app.SenderRejoined(a, sender, lost.sender)
+	} else {
+		// Not senderLeft since the rejoined sender is still here
+		a.app.SenderLeft(a, lost.sender)
+		a.app.SenderJoined(a, sender)
+	}
+	return sender
+}
+
+// removeSender removes the sender. If lost and the server has a rejoin grace period, the sender is kept as lost and
+// the app is not told it left until the period passes without a rejoin. Once it has left, the app may be stopped
+// according to the server's stop policy.
+func (a *AppSession) removeSender(conn *Conn, senderID string, lost bool) *AppSender {
+	gracePeriod := a.server.rejoinGracePeriod
+	a.sendersLock.Lock()
+	wasConnected := a.senderConnectedLocked()
+	key := senderKey{conn, senderID}
+	sender := a.senders[key]
+	// A sender with the same ID already waiting to rejoin is replaced, so it has left
+	var replaced *AppSender
+	if sender != nil {
+		delete(a.senders, key)
+		if lost && gracePeriod > 0 {
+			log.Debugf("Sender %v lost from session %v, waiting %v for rejoin", senderID, a.SessionID, gracePeriod)
+			if prev := a.lostSenders[senderID]; prev != nil && prev.timer.Stop() {
+				replaced = prev.sender
+			}
+			a.lostSenders[senderID] = &lostSender{
+				sender: sender,
+				timer:  time.AfterFunc(gracePeriod, func() { a.expireLostSender(sender) }),
+			}
+		} else {
+			log.Debugf("Sender %v left session %v", senderID, a.SessionID)
+		}
+	}
+	statusChanged := wasConnected != a.senderConnectedLocked()
+	a.sendersLock.Unlock()
+	if statusChanged {
+		a.server.syncApplications(nil, "")
+	}
+	if sender == nil {
+		return nil
+	}
+	if replaced != nil {
+		a.senderLeft(replaced)
+	}
+	if lost && gracePeriod > 0 {
+		if app, ok := a.app.(AppWithSenderRejoin); ok {
+			app.SenderLost(a, sender)
+		}
+	} else {
+		a.senderLeft(sender)
+	}
+	return sender
+}
+
+// senderLeft tells the app the sender left and then applies the stop policy
+func (a *AppSession) senderLeft(sender *AppSender) {
+	if a.app != nil {
+		a.app.SenderLeft(a, sender)
+	}
+	policy := a.server.appStopPolicy
+	if policy == AppStopNever || !policy.counts(sender) {
+		return
+	}
+	a.sendersLock.RLock()
+	remaining := a.remainingLocked(policy)
+	a.sendersLock.RUnlock()
+	if !remaining {
+		log.Debugf("Last sender left session %v, stopping", a.SessionID)
+		a.server.StopApp(a.SessionID)
+	}
+}
+
+func (a *AppSession) expireLostSender(sender *AppSender) {
+	a.sendersLock.Lock()
+	lost := a.lostSenders[sender.SenderID]
+	expired := lost != nil && lost.sender == sender
+	if expired {
+		delete(a.lostSenders, sender.SenderID)
+		log.Debugf("Sender %v did not rejoin session %v, it has left", sender.SenderID, a.SessionID)
+	}
+	a.sendersLock.Unlock()
+	if expired {
+		a.senderLeft(sender)
+	}
+}
+
+// handleMessage is called for every message sent to the session's transport ID except on the connection namespace
+func (a *AppSession) handleMessage(conn *Conn, msg Message) error {
+	if a.app == nil {
+		log.Debugf("Ignoring message for session %v: %v", a.SessionID, msg.CastMessage())
+		return nil
+	}
+	senderID := msg.CastMessage().GetSourceId()
+	sender := a.sender(conn, senderID)
+	if sender == nil {
+		log.Debugf("Ignoring message for session %v from unjoined sender %v", a.SessionID, senderID)
+		return nil
+	}
+	return a.app.HandleMessage(a, sender, msg)
+}
+
+// stopped closes the virtual connections of all joined senders and stops the app. SenderLeft is not called for them.
+func (a *AppSession) stopped() {
+	a.sendersLock.Lock()
+	senders := a.senders
+	a.senders = map[senderKey]*AppSender{}
+	for _, lost := range a.lostSenders {
+		lost.timer.Stop()
+	}
+	a.lostSenders = map[string]*lostSender{}
+	a.sendersLock.Unlock()
+	for _, sender := range senders {
+		if sender.Conn.Disconnect(a.TransportID, sender.SenderID) != nil {
+			err := sender.Conn.SendPayload(a.TransportID, sender.SenderID, NamespaceConnection, closePayload)
+			if err != nil {
+				log.Debugf("Failed closing %v on stop: %v", sender.SenderID, err)
+			}
+		}
+	}
+	if a.app != nil {
+		a.app.Stopped(a)
+	}
+}
+
+// AppStopPolicy is when to stop an app as senders leave. Lost senders that may still rejoin have not left yet.
+type AppStopPolicy int
+
+const (
+	// Stop when a strong sender leaves and no other strong sender remains
+	AppStopOnLastStrongSender AppStopPolicy = iota
+	// Stop when a strong or weak sender leaves and no other strong or weak sender remains
+	AppStopOnLastSender
+	// Only stop on STOP, a new launch or StopApp
+	AppStopNever
+)
+
+// counts is whether the sender keeps the app running under the policy
+func (p AppStopPolicy) counts(sender *AppSender) bool {
+	switch p {
+	case AppStopOnLastStrongSender:
+		return sender.ConnType == VirtualConnStrong
+	case AppStopOnLastSender:
+		return sender.ConnType != VirtualConnInvisible
+	default:
+		return true
+	}
+}
+
+var ErrAppUnavailable = errors.New("App not available")
+
+// LaunchApp stops any running app, launches a new session and broadcasts the new receiver status. If the app is not
+// in the registry or is unavailable, ErrAppUnavailable is returned.
+func (s *Server) LaunchApp(appID string) (*AppSession, error) {
+	return s.launchApp(appID, nil, "")
+}
+
+func (s *Server) launchApp(appID string, exceptConn *Conn, exceptRemoteID string) (*AppSession, error) {
+	app := s.apps.App(AppID(appID))
+	if app == nil || app.Unavailable {
+		return nil, ErrAppUnavailable
+	}
+	session := newAppSession(s, app)
+	if session.app != nil {
+		if err := session.app.Launched(session); err != nil {
+			if _, ok := err.(*ReceiverError); ok {
+				return nil, err
+			}
+			return nil, fmt.Errorf("Failed launching app: %v", err)
+		}
+	}
+	s.sessionsLock.Lock()
+	// Only one app runs at a time
+	prev := s.sessions
+	s.sessions = map[string]*AppSession{session.SessionID: session}
+	s.sessionsLock.Unlock()
+	for _, prevSession := range prev {
+		log.Debugf("Stopping session %v for new launch", prevSession.SessionID)
+		prevSession.stopped()
+	}
+	log.Debugf("Launched app %v as session %v on transport %v", appID, session.SessionID, session.TransportID)
+	s.syncApplications(exceptConn, exceptRemoteID)
+	return session, nil
+}
+
+// StopApp stops the session and broadcasts the new receiver status, returning false if the session is not running
+func (s *Server) StopApp(sessionID string) bool {
+	return s.stopApp(sessionID, nil, "")
+}
+
+func (s *Server) stopApp(sessionID string, exceptConn *Conn, exceptRemoteID string) bool {
+	s.sessionsLock.Lock()
+	session := s.sessions[sessionID]
+	delete(s.sessions, sessionID)
+	s.sessionsLock.Unlock()
+	if session == nil {
+		return false
+	}
+	log.Debugf("Stopping session %v", sessionID)
+	session.stopped()
+	s.syncApplications(exceptConn, exceptRemoteID)
+	return true
+}
+
+// Session returns the running session for the ID or nil if not running
+func (s *Server) Session(sessionID string) *AppSession {
+	s.sessionsLock.RLock()
+	defer s.sessionsLock.RUnlock()
+	return s.sessions[sessionID]
+}
+
+// SessionByTransportID returns the running session for the transport ID or nil if not running
+func (s *Server) SessionByTransportID(transportID string) *AppSession {
+	s.sessionsLock.RLock()
+	defer s.sessionsLock.RUnlock()
+	for _, session := range s.sessions {
+		if session.TransportID == transportID {
+			return session
+		}
+	}
+	return nil
+}
+
+// Sessions returns a snapshot of the running sessions
+func (s *Server) Sessions() []*AppSession {
+	s.sessionsLock.RLock()
+	defer s.sessionsLock.RUnlock()
+	ret := make([]*AppSession, 0, len(s.sessions))
+	for _, session := range s.sessions {
+		ret = append(ret, session)
+	}
+	return ret
+}
+
+// syncApplications sets the receiver status applications from the running sessions
+func (s *Server) syncApplications(exceptConn *Conn, exceptRemoteID string) *ReceiverStatus {
+	return s.updateReceiverStatus(exceptConn, exceptRemoteID, func(status *ReceiverStatus) bool {
+		status.Applications = []*ApplicationSession{}
+		for _, session := range s.Sessions() {
+			status.Applications = append(status.Applications, session.applicationSession())
+		}
+		return true
+	})
+}
+
+// senderConnected joins the sender to the session if the virtual conn is to a session's transport ID
+func (s *Server) senderConnected(conn *Conn, vc *VirtualConn) {
+	if session := s.SessionByTransportID(vc.LocalID); session != nil {
+		session.addSender(conn, vc.RemoteID, vc.Type)
+	}
+}
+
+// senderDisconnected removes the sender from the session if the virtual conn was to a session's transport ID. Lost is
+// true if the virtual conn ended without CLOSE, e.g. because the conn was lost.
+func (s *Server) senderDisconnected(conn *Conn, vc *VirtualConn, lost bool) {
+	if session := s.SessionByTransportID(vc.LocalID); session != nil {
+		session.removeSender(conn, vc.RemoteID, lost)
+	}
+}
+
+func newUUID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(fmt.Errorf("Unable to create UUID: %v", err))
+	}
+	// Version 4, variant 1
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%X-%X-%X-%X-%X", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
@@ -0,0 +1,219 @@
+package server
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+// sessionTestApp records what it is told as "<event> <senderID>"
+type sessionTestApp struct {
+	BaseApp
+	events chan string
+}
+
+func (s *sessionTestApp) SenderJoined(session *AppSession, sender *AppSender) {
+	s.events <- "joined " + sender.SenderID
+}
+
+func (s *sessionTestApp) SenderLeft(session *AppSession, sender *AppSender) {
+	s.events <- "left " + sender.SenderID
+}
+
+func (s *sessionTestApp) Stopped(session *AppSession) { s.events <- "stopped" }
+
+// sessionTestRejoinApp is a sessionTestApp told about lost senders
+type sessionTestRejoinApp struct{ sessionTestApp }
+
+func (s *sessionTestRejoinApp) SenderLost(session *AppSession, sender *AppSender) {
+	s.events <- "lost " + sender.SenderID
+}
+
+func (s *sessionTestRejoinApp) SenderRejoined(session *AppSession, sender *AppSender, previous *AppSender) {
+	s.events <- "rejoined " + sender.SenderID
+}
+
+func (s *sessionTestApp) expect(t *testing.T, events ...string) {
+	t.Helper()
+	for _, expected := range events {
+		select {
+		case event := <-s.events:
+			if event != expected {
+				t.Fatalf("Expected %v, got %v", expected, event)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("Timed out waiting for %v", expected)
+		}
+	}
+}
+
+func (s *sessionTestApp) expectNone(t *testing.T) {
+	t.Helper()
+	select {
+	case event := <-s.events:
+		t.Fatalf("Expected no event, got %v", event)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func launchSessionTestApp(t *testing.T, s *Server, app App) *AppSession {
+	s.apps.Register(&AppInfo{AppID: "TEST", NewApp: func() App { return app }})
+	session, err := s.LaunchApp("TEST")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return session
+}
+
+func connectSessionTestSender(
+	t *testing.T,
+	s *Server,
+	session *AppSession,
+	senderID string,
+	connType VirtualConnType,
+) *pipeTestPeer {
+	_, peer := newPipeTestConn(t, s)
+	connect := fmt.Sprintf(`{"type":"CONNECT","connType":%d}`, connType)
+	peer.send(senderID, session.TransportID, NamespaceConnection, connect)
+	return peer
+}
+
+func TestSessionSenderLostAndRejoined(t *testing.T) {
+	s := newPipeTestServer()
+	app := &sessionTestRejoinApp{sessionTestApp{events: make(chan string, 10)}}
+	session := launchSessionTestApp(t, s, app)
+	connectSessionTestSender(t, s, session, "sender-1", VirtualConnStrong).close()
+	app.expect(t, "joined sender-1", "lost sender-1")
+	// Still running for the lost strong sender
+	if s.Session(session.SessionID) == nil {
+		t.Fatal("Expected session running")
+	}
+	peer := connectSessionTestSender(t, s, session, "sender-1", VirtualConnStrong)
+	defer peer.close()
+	app.expect(t, "rejoined sender-1")
+	app.expectNone(t)
+	session.sendersLock.RLock()
+	joined, lost := len(session.senders), len(session.lostSenders)
+	session.sendersLock.RUnlock()
+	if joined != 1 || lost != 0 {
+		t.Fatalf("Expected one joined sender, got %v and %v lost", joined, lost)
+	}
+}
+
+func TestSessionSenderRejoinedWithoutRejoinApp(t *testing.T) {
+	s := newPipeTestServer()
+	app := &sessionTestApp{events: make(chan string, 10)}
+	session := launchSessionTestApp(t, s, app)
+	connectSessionTestSender(t, s, session, "sender-1", VirtualConnStrong).close()
+	app.expect(t, "joined sender-1")
+	app.expectNone(t)
+	peer := connectSessionTestSender(t, s, session, "sender-1", VirtualConnStrong)
+	defer peer.close()
+	app.expect(t, "left sender-1", "joined sender-1")
+}
+
+func TestSessionLostSenderExpires(t *testing.T) {
+	s := newPipeTestServer()
+	s.rejoinGracePeriod = 50 * time.Millisecond
+	app := &sessionTestRejoinApp{sessionTestApp{events: make(chan string, 10)}}
+	session := launchSessionTestApp(t, s, app)
+	connectSessionTestSender(t, s, session, "sender-1", VirtualConnStrong).close()
+	app.expect(t, "joined sender-1", "lost sender-1", "left sender-1", "stopped")
+	if s.Session(session.SessionID) != nil {
+		t.Fatal("Expected session stopped")
+	}
+}
+
+func TestSessionLostSenderReplaced(t *testing.T) {
+	s := newPipeTestServer()
+	app := &sessionTestRejoinApp{sessionTestApp{events: make(chan string, 10)}}
+	session := launchSessionTestApp(t, s, app)
+	first := connectSessionTestSender(t, s, session, "sender-1", VirtualConnStrong)
+	app.expect(t, "joined sender-1")
+	second := connectSessionTestSender(t, s, session, "sender-1", VirtualConnStrong)
+	app.expect(t, "joined sender-1")
+	first.close()
+	app.expect(t, "lost sender-1")
+	// The first lost sender can no longer rejoin, so it has left
+	second.close()
+	app.expect(t, "left sender-1", "lost sender-1")
+	app.expectNone(t)
+}
+
+func TestSessionSenderClosed(t *testing.T) {
+	s := newPipeTestServer()
+	app := &sessionTestRejoinApp{sessionTestApp{events: make(chan string, 10)}}
+	session := launchSessionTestApp(t, s, app)
+	peer := connectSessionTestSender(t, s, session, "sender-1", VirtualConnStrong)
+	defer peer.close()
+	peer.send("sender-1", session.TransportID, NamespaceConnection, `{"type":"CLOSE"}`)
+	app.expect(t, "joined sender-1", "left sender-1", "stopped")
+}
+
+func TestSessionReceiverClosed(t *testing.T) {
+	s := newPipeTestServer()
+	app := &sessionTestRejoinApp{sessionTestApp{events: make(chan string, 10)}}
+	session := launchSessionTestApp(t, s, app)
+	conn, peer := newPipeTestConn(t, s)
+	defer peer.close()
+	peer.send("sender-1", session.TransportID, NamespaceConnection, `{"type":"CONNECT"}`)
+	app.expect(t, "joined sender-1")
+	// Closed by the receiver, so there is nothing to rejoin
+	if err := conn.CloseVirtualConns(); err != nil {
+		t.Fatal(err)
+	}
+	app.expect(t, "left sender-1", "stopped")
+}
+
+func TestSessionStopPolicy(t *testing.T) {
+	tests := []struct {
+		policy      AppStopPolicy
+		connType    VirtualConnType
+		expectedRun bool
+	}{
+		{AppStopOnLastStrongSender, VirtualConnStrong, false},
+		{AppStopOnLastStrongSender, VirtualConnWeak, true},
+		{AppStopOnLastSender, VirtualConnStrong, false},
+		{AppStopOnLastSender, VirtualConnWeak, false},
+		{AppStopOnLastSender, VirtualConnInvisible, true},
+		{AppStopNever, VirtualConnStrong, true},
+	}
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("policy %v conn type %v", test.policy, test.connType), func(t *testing.T) {
+			s := newPipeTestServer()
+			s.appStopPolicy = test.policy
+			app := &sessionTestApp{events: make(chan string, 10)}
+			session := launchSessionTestApp(t, s, app)
+			peer := connectSessionTestSender(t, s, session, "sender-1", test.connType)
+			defer peer.close()
+			app.expect(t, "joined sender-1")
+			peer.send("sender-1", session.TransportID, NamespaceConnection, `{"type":"CLOSE"}`)
+			app.expect(t, "left sender-1")
+			if test.expectedRun {
+				app.expectNone(t)
+			} else {
+				app.expect(t, "stopped")
+			}
+			if running := s.Session(session.SessionID) != nil; running != test.expectedRun {
+				t.Fatalf("Expected running %v, got %v", test.expectedRun, running)
+			}
+		})
+	}
+}
+
+func TestSessionStopPolicyCountsOtherSenders(t *testing.T) {
+	s := newPipeTestServer()
+	app := &sessionTestApp{events: make(chan string, 10)}
+	session := launchSessionTestApp(t, s, app)
+	first := connectSessionTestSender(t, s, session, "sender-1", VirtualConnStrong)
+	defer first.close()
+	app.expect(t, "joined sender-1")
+	second := connectSessionTestSender(t, s, session, "sender-2", VirtualConnStrong)
+	defer second.close()
+	app.expect(t, "joined sender-2")
+	first.send("sender-1", session.TransportID, NamespaceConnection, `{"type":"CLOSE"}`)
+	app.expect(t, "left sender-1")
+	app.expectNone(t)
+	second.send("sender-2", session.TransportID, NamespaceConnection, `{"type":"CLOSE"}`)
+	app.expect(t, "left sender-2", "stopped")
+}
@@ -0,0 +1,95 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"os"
+
+	"github.com/cretz/owncast/owncast/log"
+)
+
+// VolumeHook is called with every new volume, in order, e.g. to drive a real mixer. It must not change the volume.
+type VolumeHook func(volume Volume)
+
+func (s *Server) initVolume(conf *Conf) error {
+	volume := s.receiverStatus.Volume
+	volume.ControlType = conf.VolumeControlType
+	if volume.ControlType == "" {
+		volume.ControlType = VolumeControlAttenuation
+	}
+	volume.StepInterval = conf.VolumeStepInterval
+	if volume.StepInterval <= 0 {
+		volume.StepInterval = 0.05
+	}
+	if s.volumeFile == "" {
+		return nil
+	}
+	byts, err := ioutil.ReadFile(s.volumeFile)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("Failed reading volume file: %v", err)
+	}
+	var saved VolumeRequest
+	if err = json.Unmarshal(byts, &saved); err != nil {
+		return fmt.Errorf("Failed parsing volume file: %v", err)
+	}
+	log.Debugf("Loaded volume from %v", s.volumeFile)
+	applyVolumeRequest(volume, &saved)
+	return nil
+}
+
+// SetVolume changes the volume the same way SET_VOLUME does, e.g. when a real mixer is changed, and broadcasts the
+// new receiver status if it changed
+func (s *Server) SetVolume(req *VolumeRequest) *ReceiverStatus { return s.setVolume(nil, "", req) }
+
+func (s *Server) setVolume(exceptConn *Conn, exceptRemoteID string, req *VolumeRequest) *ReceiverStatus {
+	// Serialize changes so the hook and file see them in order
+	s.volumeLock.Lock()
+	defer s.volumeLock.Unlock()
+	var changed *Volume
+	status := s.updateReceiverStatus(exceptConn, exceptRemoteID, func(status *ReceiverStatus) bool {
+		prev := *status.Volume
+		if status.Volume.ControlType == VolumeControlFixed {
+			log.Debugf("Ignoring volume change on fixed volume")
+		} else {
+			applyVolumeRequest(status.Volume, req)
+		}
+		if prev == *status.Volume {
+			return false
+		}
+		volume := *status.Volume
+		changed = &volume
+		return true
+	})
+	if changed != nil {
+		if s.volumeFile != "" {
+			if err := saveVolume(s.volumeFile, changed); err != nil {
+				log.Infof("Failed saving volume: %v", err)
+			}
+		}
+		if s.volumeHook != nil {
+			s.volumeHook(*changed)
+		}
+	}
+	return status
+}
+
+func applyVolumeRequest(volume *Volume, req *VolumeRequest) {
+	if req.Level != nil {
+		volume.Level = math.Max(0, math.Min(1, *req.Level))
+	}
+	if req.Muted != nil {
+		volume.Muted = *req.Muted
+	}
+}
+
+func saveVolume(file string, volume *Volume) error {
+	byts, err := json.Marshal(&VolumeRequest{Level: &volume.Level, Muted: &volume.Muted})
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(file, byts, 0644)
+}
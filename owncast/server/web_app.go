@@ -0,0 +1,182 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cretz/owncast/owncast/log"
+	"golang.org/x/net/websocket"
+)
+
+// WebApp is an App whose receiver page is served from AppInfo.Dir by WebAppHandler. Pages at /apps/<appId>/ load the
+// shim at /owncast/cast_receiver.js which provides cast.receiver.CastReceiverManager and message buses over a
+// WebSocket to /owncast/ws/<appId>. The WebSocket carries AppLine JSON just like ProcessApp's stdio. Only messages on
+// the session's namespaces are forwarded and every connected page gets them.
+type WebApp struct {
+	session   *AppSession
+	pages     map[*websocket.Conn]struct{}
+	pagesLock sync.Mutex
+	stopped   bool
+}
+
+func NewWebApp() *WebApp {
+	return &WebApp{pages: map[*websocket.Conn]struct{}{}}
+}
+
+func (w *WebApp) Launched(session *AppSession) error {
+	w.session = session
+	if addr := session.server.HTTPAddr(); addr != "" {
+		log.Infof("Web app %v launched, open http://%v/apps/%v/", session.AppID, addr, session.AppID)
+	}
+	return nil
+}
+
+func (w *WebApp) SenderJoined(session *AppSession, sender *AppSender) {
+	w.broadcast(&AppLine{Type: "senderJoined", SenderID: sender.SenderID})
+}
+
+func (w *WebApp) SenderLeft(session *AppSession, sender *AppSender) {
+	w.broadcast(&AppLine{Type: "senderLeft", SenderID: sender.SenderID})
+}
+
+func (w *WebApp) HandleMessage(session *AppSession, sender *AppSender, msg Message) error {
+	castMsg := msg.CastMessage()
+	if !session.hasNamespace(castMsg.GetNamespace()) {
+		log.Debugf("Ignoring message for web app on namespace %v", castMsg.GetNamespace())
+		return nil
+	}
+	w.broadcast(messageLine(sender, castMsg))
+	return nil
+}
+
+func (w *WebApp) Stopped(session *AppSession) {
+	w.broadcast(&AppLine{Type: "stopped"})
+	w.pagesLock.Lock()
+	defer w.pagesLock.Unlock()
+	w.stopped = true
+	for page := range w.pages {
+		page.Close()
+	}
+	w.pages = map[*websocket.Conn]struct{}{}
+}
+
+func (w *WebApp) broadcast(line *AppLine) {
+	w.pagesLock.Lock()
+	defer w.pagesLock.Unlock()
+	for page := range w.pages {
+		if err := w.send(page, line); err != nil {
+			log.Debugf("Failed sending to web app page, closing: %v", err)
+			page.Close()
+			delete(w.pages, page)
+		}
+	}
+}
+
+// send must be called with pagesLock held
+func (w *WebApp) send(page *websocket.Conn, line *AppLine) error {
+	page.SetWriteDeadline(time.Now().Add(w.session.server.writeTimeout))
+	return websocket.JSON.Send(page, line)
+}
+
+// servePage tells the page about the session and then sends what it receives to the senders until it disconnects
+func (w *WebApp) servePage(page *websocket.Conn) {
+	defer page.Close()
+	w.pagesLock.Lock()
+	if w.stopped {
+		w.pagesLock.Unlock()
+		return
+	}
+	err := w.send(page, w.session.launchedLine())
+	for _, sender := range w.session.Senders() {
+		if err == nil {
+			err = w.send(page, &AppLine{Type: "senderJoined", SenderID: sender.SenderID})
+		}
+	}
+	if err == nil {
+		w.pages[page] = struct{}{}
+	}
+	w.pagesLock.Unlock()
+	if err != nil {
+		log.Debugf("Failed sending to web app page: %v", err)
+		return
+	}
+	defer func() {
+		w.pagesLock.Lock()
+		delete(w.pages, page)
+		w.pagesLock.Unlock()
+	}()
+	for {
+		line := &AppLine{}
+		if err := websocket.JSON.Receive(page, line); err != nil {
+			log.Debugf("Web app page for session %v disconnected: %v", w.session.SessionID, err)
+			return
+		}
+		if err := w.session.sendAppLine(line); err != nil {
+			log.Debugf("Bad message from web app page: %v", err)
+		}
+	}
+}
+
+// WebAppHandler serves registered apps' Dir at /apps/<appId>/, the receiver shim at /owncast/cast_receiver.js and
+// page WebSockets at /owncast/ws/<appId>. It is served on Conf.HTTPListenAddr if set but can be mounted elsewhere.
+// WebSockets are only accepted from pages served by the same host so other pages in a browser can't act as the app.
+func (s *Server) WebAppHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/owncast/cast_receiver.js", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/javascript")
+		w.Write([]byte(webAppShimJS))
+	})
+	mux.Handle("/owncast/ws/", websocket.Server{
+		Handshake: checkWebAppOrigin,
+		Handler: func(page *websocket.Conn) {
+			appID := strings.TrimPrefix(page.Request().URL.Path, "/owncast/ws/")
+			for _, session := range s.Sessions() {
+				if webApp, ok := session.App().(*WebApp); ok && session.AppID == appID {
+					webApp.servePage(page)
+					return
+				}
+			}
+			log.Debugf("Web app page connected for %v which is not running", appID)
+			page.Close()
+		},
+	})
+	mux.HandleFunc("/apps/", func(w http.ResponseWriter, r *http.Request) {
+		pieces := strings.SplitN(strings.TrimPrefix(r.URL.Path, "/apps/"), "/", 2)
+		app := s.apps.App(AppID(pieces[0]))
+		if app == nil || app.Dir == "" {
+			http.NotFound(w, r)
+			return
+		}
+		prefix := "/apps/" + pieces[0]
+		if len(pieces) == 1 {
+			http.Redirect(w, r, prefix+"/", http.StatusMovedPermanently)
+			return
+		}
+		http.StripPrefix(prefix, http.FileServer(http.Dir(app.Dir))).ServeHTTP(w, r)
+	})
+	return mux
+}
+
+// checkWebAppOrigin rejects WebSockets without an origin or from a different host than the one connected to
+func checkWebAppOrigin(config *websocket.Config, r *http.Request) error {
+	origin, err := websocket.Origin(config, r)
+	if err != nil {
+		return err
+	} else if origin == nil || origin.Host != r.Host {
+		log.Debugf("Rejecting web app page WebSocket from origin %v", origin)
+		return fmt.Errorf("Origin %v not allowed", origin)
+	}
+	config.Origin = origin
+	return nil
+}
+
+// HTTPAddr is the address web apps are served on or empty if Conf.HTTPListenAddr was not set
+func (s *Server) HTTPAddr() string {
+	if s.httpListener == nil {
+		return ""
+	}
+	return s.httpListener.Addr().String()
+}
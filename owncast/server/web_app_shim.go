@@ -0,0 +1,151 @@
+package server
+
+// webAppShimJS mimics the parts of the Cast receiver framework's cast.receiver namespace that web receiver apps
+// commonly use, backed by the WebApp WebSocket
+const webAppShimJS = `(function() {
+  'use strict';
+  var cast = window.cast = window.cast || {};
+  var receiver = cast.receiver = cast.receiver || {};
+  var manager = null;
+
+  function CastMessageBus(namespace, messageType) {
+    this.namespace_ = namespace;
+    this.messageType_ = messageType || CastMessageBus.MessageType.STRING;
+    this.onMessage = null;
+  }
+  CastMessageBus.MessageType = { STRING: 'STRING', JSON: 'JSON', CUSTOM: 'CUSTOM' };
+  CastMessageBus.prototype.getNamespace = function() { return this.namespace_; };
+  CastMessageBus.prototype.getMessageType = function() { return this.messageType_; };
+  CastMessageBus.prototype.send = function(senderId, message) {
+    if (this.messageType_ === CastMessageBus.MessageType.JSON) {
+      message = JSON.stringify(message);
+    }
+    CastReceiverManager.getInstance().send_({
+      type: 'message', senderId: senderId, namespace: this.namespace_, data: message
+    });
+  };
+  CastMessageBus.prototype.broadcast = function(message) { this.send('*', message); };
+  CastMessageBus.prototype.getCastChannel = function(senderId) {
+    var bus = this;
+    return {
+      getSenderId: function() { return senderId; },
+      send: function(message) { bus.send(senderId, message); }
+    };
+  };
+  CastMessageBus.prototype.dispatch_ = function(line) {
+    var data = line.data;
+    if (data === undefined) {
+      data = line.binary;
+    } else if (this.messageType_ === CastMessageBus.MessageType.JSON) {
+      try {
+        data = JSON.parse(data);
+      } catch (e) {
+        console.warn('Invalid JSON from ' + line.senderId, e);
+        return;
+      }
+    }
+    if (this.onMessage) {
+      this.onMessage({ type: 'message', senderId: line.senderId, data: data });
+    }
+  };
+
+  function CastReceiverManager() {
+    this.onReady = null;
+    this.onSenderConnected = null;
+    this.onSenderDisconnected = null;
+    this.onShutdown = null;
+    this.buses_ = {};
+    this.senders_ = {};
+    this.applicationData_ = null;
+    this.socket_ = null;
+    this.stopped_ = false;
+  }
+  CastReceiverManager.getInstance = function() {
+    if (!manager) {
+      manager = new CastReceiverManager();
+    }
+    return manager;
+  };
+  CastReceiverManager.prototype.getCastMessageBus = function(namespace, messageType) {
+    if (!this.buses_[namespace]) {
+      this.buses_[namespace] = new CastMessageBus(namespace, messageType);
+    }
+    return this.buses_[namespace];
+  };
+  CastReceiverManager.prototype.getApplicationData = function() { return this.applicationData_; };
+  CastReceiverManager.prototype.getSenders = function() { return Object.keys(this.senders_); };
+  CastReceiverManager.prototype.getSender = function(senderId) { return this.senders_[senderId] || null; };
+  CastReceiverManager.prototype.setApplicationState = function(statusText) {};
+  CastReceiverManager.prototype.start = function() {
+    this.stopped_ = false;
+    this.connect_();
+  };
+  CastReceiverManager.prototype.stop = function() {
+    this.stopped_ = true;
+    if (this.socket_) {
+      this.socket_.close();
+    }
+  };
+  CastReceiverManager.prototype.send_ = function(line) {
+    if (!this.socket_ || this.socket_.readyState !== WebSocket.OPEN) {
+      console.warn('Not connected, dropping message', line);
+      return;
+    }
+    this.socket_.send(JSON.stringify(line));
+  };
+  CastReceiverManager.prototype.connect_ = function() {
+    var self = this;
+    // Pages are served at /apps/<appId>/
+    var appId = location.pathname.split('/')[2];
+    var scheme = location.protocol === 'https:' ? 'wss:' : 'ws:';
+    var socket = this.socket_ = new WebSocket(scheme + '//' + location.host + '/owncast/ws/' + appId);
+    socket.onmessage = function(event) { self.handle_(JSON.parse(event.data)); };
+    socket.onclose = function() {
+      self.socket_ = null;
+      // The app may not be launched yet or may be relaunched later
+      if (!self.stopped_) {
+        setTimeout(function() { self.connect_(); }, 1000);
+      }
+    };
+  };
+  CastReceiverManager.prototype.handle_ = function(line) {
+    switch (line.type) {
+    case 'launched':
+      this.senders_ = {};
+      this.applicationData_ = {
+        id: line.appId, name: line.displayName, namespaces: line.namespaces || [], sessionId: line.sessionId
+      };
+      if (this.onReady) {
+        this.onReady({ data: this.applicationData_ });
+      }
+      break;
+    case 'senderJoined':
+      this.senders_[line.senderId] = { id: line.senderId, userAgent: '' };
+      if (this.onSenderConnected) {
+        this.onSenderConnected({ senderId: line.senderId, userAgent: '' });
+      }
+      break;
+    case 'senderLeft':
+      delete this.senders_[line.senderId];
+      if (this.onSenderDisconnected) {
+        this.onSenderDisconnected({ senderId: line.senderId, reason: 'requested_by_sender' });
+      }
+      break;
+    case 'message':
+      var bus = this.buses_[line.namespace];
+      if (bus) {
+        bus.dispatch_(line);
+      }
+      break;
+    case 'stopped':
+      if (this.onShutdown) {
+        this.onShutdown({});
+      }
+      break;
+    }
+  };
+
+  receiver.CastMessageBus = CastMessageBus;
+  receiver.CastReceiverManager = CastReceiverManager;
+})();
+`